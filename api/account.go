@@ -1,85 +1,97 @@
 package api
 
 import (
-	"database/sql"
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/money"
 	"github.com/mateusribs/simple_bank/token"
 )
 
+type accountResponse struct {
+	db.Account
+	BalanceDisplay string `json:"balance_display"`
+	AvailableBalanceDisplay string `json:"available_balance_display"`
+}
+
+// adds the balances formatted for the locale of the Accept-Language header
+func newAccountResponse(ctx *gin.Context, account db.Account) accountResponse {
+	locale := ctx.GetHeader("Accept-Language")
+
+	return accountResponse{
+		Account: account,
+		BalanceDisplay: money.Money{Amount: account.Balance, Currency: account.Currency}.Format(locale),
+		AvailableBalanceDisplay: money.Money{Amount: account.AvailableBalance, Currency: account.Currency}.Format(locale),
+	}
+}
+
+var errAccountNotOwned = newError(http.StatusUnauthorized, codeNotOwner, "account does not belong to the authenticated user")
+
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	Product string `json:"product" binding:"omitempty,alphanum"`
 }
 
-func (server *Server) createAccount(ctx *gin.Context) {
+func (server *Server) createAccount(ctx *gin.Context) error {
 	var req createAccountRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return invalidRequest(err)
+	}
+
+	if err := server.checkCurrency(req.Currency); err != nil {
+		return err
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
+	if req.Product == "" {
+		req.Product = db.ProductChecking
+	}
+
 	arg := db.CreateAccountParams{
 		Owner: authPayload.Username,
 		Currency: req.Currency,
 		Balance: 0,
+		Product: req.Product,
 	}
 
 	account, err := server.store.CreateAccount(ctx, arg)
 
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "foreign_key_violation", "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return err
 	}
 
-	ctx.JSON(http.StatusOK, account)
-
+	ctx.JSON(http.StatusOK, newAccountResponse(ctx, account))
+	return nil
 }
 
 type getAccountRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
-func (server *Server) getAccount(ctx *gin.Context){
+func (server *Server) getAccount(ctx *gin.Context) error {
 	var req getAccountRequest
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return invalidRequest(err)
 	}
 
 	account, err := server.store.GetAccount(ctx, req.ID)
+
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return orNotFound(err, errAccountNotFound)
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
 	if account.Owner != authPayload.Username {
-		err := errors.New("account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+		return errAccountNotOwned
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, newAccountResponse(ctx, account))
+	return nil
 }
 
 type listAccountRequest struct {
@@ -87,12 +99,11 @@ type listAccountRequest struct {
 	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
 }
 
-func (server *Server) listAccount(ctx *gin.Context){
+func (server *Server) listAccount(ctx *gin.Context) error {
 	var req listAccountRequest
 
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return invalidRequest(err)
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
@@ -106,11 +117,17 @@ func (server *Server) listAccount(ctx *gin.Context){
 	accounts, err := server.store.ListAccounts(ctx, arg)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return err
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	rsp := make([]accountResponse, len(accounts))
+
+	for i, account := range accounts {
+		rsp[i] = newAccountResponse(ctx, account)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+	return nil
 }
 
 
@@ -119,12 +136,11 @@ type updateAccountRequest struct {
 	Balance int64 `json:"balance" binding:"required,min=0"`
 }
 
-func (server *Server) updateAccount(ctx *gin.Context){
+func (server *Server) updateAccount(ctx *gin.Context) error {
 	var req updateAccountRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return invalidRequest(err)
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
@@ -138,9 +154,9 @@ func (server *Server) updateAccount(ctx *gin.Context){
 	account, err := server.store.UpdateAccount(ctx, arg)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return err
 	}
 
-	ctx.JSON(http.StatusOK, account)
-}
\ No newline at end of file
+	ctx.JSON(http.StatusOK, newAccountResponse(ctx, account))
+	return nil
+}
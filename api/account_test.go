@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"fmt"
 	"io"
@@ -12,23 +13,74 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/goccy/go-json"
-	mockdb "github.com/mateusribs/simple_bank/db/mock"
 	db "github.com/mateusribs/simple_bank/db/sqlc"
 	"github.com/mateusribs/simple_bank/token"
 	"github.com/mateusribs/simple_bank/util"
 	"github.com/stretchr/testify/require"
-	"go.uber.org/mock/gomock"
 )
 
 func randomAccount(owner string) db.Account{
+	balance := util.RandomMoney()
+
 	return db.Account{
 		ID: util.RandomInt(1, 1000),
 		Owner: owner,
-		Balance: util.RandomMoney(),
+		Balance: balance,
+		AvailableBalance: balance,
 		Currency: util.RandomCurrency(),
+		Product: db.ProductChecking,
 	}
 }
 
+// creates a user in store, as createUser would
+func createTestUser(t *testing.T, store db.Store) db.User {
+	user, _ := randomUser(t)
+
+	user, err := store.CreateUser(context.Background(), db.CreateUserParams{
+		Username: user.Username,
+		HashedPassword: user.HashedPassword,
+		FullName: user.FullName,
+		Email: user.Email,
+	})
+	require.NoError(t, err)
+
+	return user
+}
+
+// creates an account of owner in store holding balance
+func createTestAccount(t *testing.T, store db.Store, owner string, currency string, balance int64) db.Account {
+	account, err := store.CreateAccount(context.Background(), db.CreateAccountParams{
+		Owner: owner,
+		Balance: balance,
+		Currency: currency,
+		Product: db.ProductChecking,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
+// a MemStore whose account queries fail like a lost connection, which a MemStore never does
+type failingAccountStore struct {
+	*db.MemStore
+}
+
+func (store failingAccountStore) CreateAccount(ctx context.Context, arg db.CreateAccountParams) (db.Account, error) {
+	return db.Account{}, sql.ErrConnDone
+}
+
+func (store failingAccountStore) GetAccount(ctx context.Context, id int64) (db.Account, error) {
+	return db.Account{}, sql.ErrConnDone
+}
+
+func (store failingAccountStore) ListAccounts(ctx context.Context, arg db.ListAccountsParams) ([]db.Account, error) {
+	return nil, sql.ErrConnDone
+}
+
+func (store failingAccountStore) UpdateAccount(ctx context.Context, arg db.UpdateAccountParams) (db.Account, error) {
+	return db.Account{}, sql.ErrConnDone
+}
+
 func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Account){
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
@@ -38,7 +90,7 @@ func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Accoun
 	err = json.Unmarshal(data, &gotAccount)
 
 	require.NoError(t, err)
-	require.Equal(t, account, gotAccount)
+	require.Equal(t, utcAccount(account), utcAccount(gotAccount))
 }
 
 func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, accounts []db.Account){
@@ -51,18 +103,28 @@ func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, accounts []db.Ac
 
 	require.NoError(t, err)
 	require.Equal(t, len(accounts), len(gotAccounts))
-	require.Equal(t, accounts, gotAccounts)
+
+	for i := range accounts {
+		require.Equal(t, utcAccount(accounts[i]), utcAccount(gotAccounts[i]))
+	}
+}
+
+// the account with its creation time in UTC, as it comes back from JSON in any time zone
+func utcAccount(account db.Account) db.Account {
+	account.CreatedAt.Time = account.CreatedAt.Time.UTC()
+	return account
 }
 
 func TestGetAccountAPI(t *testing.T){
-	user, _ := randomUser(t)
-	account := randomAccount(user.Username)
+	store := db.NewMemStore()
+	user := createTestUser(t, store)
+	account := createTestAccount(t, store, user.Username, util.RandomCurrency(), util.RandomMoney())
 
 	testCases := []struct{
 		name string
 		accountID int64
+		failing bool
 		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
-		buildStubs func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
@@ -73,9 +135,6 @@ func TestGetAccountAPI(t *testing.T){
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				requireBodyMatchAccount(t, recorder.Body, account)
@@ -89,9 +148,6 @@ func TestGetAccountAPI(t *testing.T){
 					t, request, tokenMaker, authorizationTypeBearer, "unauthorized", time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -101,24 +157,18 @@ func TestGetAccountAPI(t *testing.T){
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
 		{
 			name: "NotFound",
-			accountID: account.ID,
+			accountID: account.ID + 1,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
@@ -126,14 +176,12 @@ func TestGetAccountAPI(t *testing.T){
 		{
 			name: "InternalError",
 			accountID: account.ID,
+			failing: true,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
@@ -146,9 +194,6 @@ func TestGetAccountAPI(t *testing.T){
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
@@ -159,16 +204,14 @@ func TestGetAccountAPI(t *testing.T){
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+			var testStore db.Store = store
 
-			store := mockdb.NewMockStore(ctrl)
+			if tc.failing {
+				testStore = failingAccountStore{store}
+			}
 
-			//build stubs
-			tc.buildStubs(store)
-			
 			//start test server and send request
-			server := newTestServer(t, store)
+			server := newTestServer(t, testStore)
 			recorder := httptest.NewRecorder()
 
 			url := fmt.Sprintf("/accounts/%d", tc.accountID)
@@ -187,90 +230,90 @@ func TestGetAccountAPI(t *testing.T){
 
 
 func TestCreateAccountAPI(t *testing.T){
-	user, _ := randomUser(t)
-	account := randomAccount(user.Username)
+	store := db.NewMemStore()
+	user := createTestUser(t, store)
+	currency := util.RandomCurrency()
 
 	testCases := []struct{
 		name string
 		body gin.H
+		failing bool
 		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
-		buildStubs func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name: "OK",
+			name: "NoAuthorization",
+			body: gin.H{
+				"currency": currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCurrency",
 			body: gin.H{
-				"currency": account.Currency,
+				"currency": "",
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.CreateAccountParams{
-					Owner: account.Owner,
-					Currency: account.Currency,
-					Balance: 0,
-				}
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchAccount(t, recorder.Body, account)
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
-			name: "NoAuthorization",
+			name: "InternalError",
 			body: gin.H{
-				"currency": account.Currency,
+				"currency": currency,
 			},
+			failing: true,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+				addAuthorization(
+					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
+				)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
-			name: "InvalidCurrency",
+			name: "OK",
 			body: gin.H{
-				"currency": "",
+				"currency": currency,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				accounts, err := store.ListAccounts(context.Background(), db.ListAccountsParams{Owner: user.Username, Limit: 5})
+				require.NoError(t, err)
+				require.Len(t, accounts, 1)
+				require.Equal(t, currency, accounts[0].Currency)
+				require.Zero(t, accounts[0].Balance)
+				requireBodyMatchAccount(t, recorder.Body, accounts[0])
 			},
 		},
 		{
-			name: "InternalError",
+			name: "DuplicateCurrency",
 			body: gin.H{
-				"currency": account.Currency,
+				"currency": currency,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.CreateAccountParams{
-					Owner: account.Owner,
-					Currency: account.Currency,
-					Balance: 0,
-				}
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Account{}, sql.ErrConnDone)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
 	}
@@ -279,16 +322,14 @@ func TestCreateAccountAPI(t *testing.T){
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+			var testStore db.Store = store
 
-			store := mockdb.NewMockStore(ctrl)
+			if tc.failing {
+				testStore = failingAccountStore{store}
+			}
 
-			//build stubs
-			tc.buildStubs(store)
-			
 			//start test server and send request
-			server := newTestServer(t, store)
+			server := newTestServer(t, testStore)
 			recorder := httptest.NewRecorder()
 
 			data, err := json.Marshal(tc.body)
@@ -310,20 +351,26 @@ func TestCreateAccountAPI(t *testing.T){
 }
 
 func TestListAccountAPI(t *testing.T){
-	user, _ := randomUser(t)
-	
-	accounts := make([]db.Account, 10)
+	store := db.NewMemStore()
+	user := createTestUser(t, store)
+
+	var accounts []db.Account
 
-	for i := 0; i < 10; i++ {
-		accounts = append(accounts, randomAccount(user.Username))
+	// an owner has one account per currency
+	for _, currency := range []string{util.USD, util.EUR, util.BRL} {
+		accounts = append(accounts, createTestAccount(t, store, user.Username, currency, util.RandomMoney()))
 	}
 
+	// accounts of other owners are never listed
+	other := createTestUser(t, store)
+	createTestAccount(t, store, other.Username, util.USD, util.RandomMoney())
+
 	testCases := []struct{
 		name string
 		page_id int32
 		page_size int32
+		failing bool
 		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
-		buildStubs func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
@@ -335,28 +382,31 @@ func TestListAccountAPI(t *testing.T){
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.ListAccountsParams{
-					Owner: user.Username,
-					Limit: 5,
-					Offset: 0,
-				}
-				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				requireBodyMatchAccounts(t, recorder.Body, accounts)
 			},
 		},
+		{
+			name: "PastLastPage",
+			page_id: 2,
+			page_size: 5,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(
+					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccounts(t, recorder.Body, []db.Account{})
+			},
+		},
 		{
 			name: "NoAuthorization",
 			page_id: 1,
 			page_size: 5,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -370,9 +420,6 @@ func TestListAccountAPI(t *testing.T){
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
@@ -386,9 +433,6 @@ func TestListAccountAPI(t *testing.T){
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
@@ -402,9 +446,6 @@ func TestListAccountAPI(t *testing.T){
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
@@ -413,19 +454,12 @@ func TestListAccountAPI(t *testing.T){
 			name: "InternalError",
 			page_id: 1,
 			page_size: 5,
+			failing: true,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.ListAccountsParams{
-					Owner: user.Username,
-					Limit: 5,
-					Offset: 0,
-				}
-				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Account{}, sql.ErrConnDone)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
@@ -436,16 +470,14 @@ func TestListAccountAPI(t *testing.T){
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+			var testStore db.Store = store
 
-			store := mockdb.NewMockStore(ctrl)
+			if tc.failing {
+				testStore = failingAccountStore{store}
+			}
 
-			//build stubs
-			tc.buildStubs(store)
-			
 			//start test server and send request
-			server := newTestServer(t, store)
+			server := newTestServer(t, testStore)
 			recorder := httptest.NewRecorder()
 
 			url := fmt.Sprintf("/accounts?page_id=%d&page_size=%d",tc.page_id, tc.page_size)
@@ -465,56 +497,49 @@ func TestListAccountAPI(t *testing.T){
 
 
 func TestUpdateAccountAPI(t *testing.T){
-	user, _ := randomUser(t)
-	account := randomAccount(user.Username)
-	//update balance
-	account.Balance = 400
+	store := db.NewMemStore()
+	user := createTestUser(t, store)
+	account := createTestAccount(t, store, user.Username, util.RandomCurrency(), util.RandomMoney())
+	balance := int64(400)
 
 	testCases := []struct{
 		name string
 		body gin.H
+		failing bool
 		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
-		buildStubs func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
 			body: gin.H{
 				"id": account.ID,
-				"balance": account.Balance,
+				"balance": balance,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.UpdateAccountParams{
-					ID: account.ID,
-					Owner: user.Username,
-					Balance: account.Balance,
-				}
-				store.EXPECT().UpdateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchAccount(t, recorder.Body, account)
+
+				updated, err := store.GetAccount(context.Background(), account.ID)
+				require.NoError(t, err)
+				require.Equal(t, balance, updated.Balance)
+				requireBodyMatchAccount(t, recorder.Body, updated)
 			},
 		},
 		{
 			name: "InvalidID",
 			body: gin.H{
 				"id": 0,
-				"balance": account.Balance,
+				"balance": balance,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().UpdateAccount(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
@@ -530,9 +555,6 @@ func TestUpdateAccountAPI(t *testing.T){
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().UpdateAccount(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
@@ -540,22 +562,15 @@ func TestUpdateAccountAPI(t *testing.T){
 		{
 			name: "InternalError",
 			body: gin.H{
-				"id": 1000,
-				"balance": account.Balance,
+				"id": account.ID,
+				"balance": balance,
 			},
+			failing: true,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.UpdateAccountParams{
-					ID: 1000,
-					Owner: user.Username,
-					Balance: account.Balance,
-				}
-				store.EXPECT().UpdateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Account{}, sql.ErrNoRows)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
@@ -566,16 +581,14 @@ func TestUpdateAccountAPI(t *testing.T){
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+			var testStore db.Store = store
 
-			store := mockdb.NewMockStore(ctrl)
+			if tc.failing {
+				testStore = failingAccountStore{store}
+			}
 
-			//build stubs
-			tc.buildStubs(store)
-			
 			//start test server and send request
-			server := newTestServer(t, store)
+			server := newTestServer(t, testStore)
 			recorder := httptest.NewRecorder()
 
 			data, err := json.Marshal(tc.body)
@@ -596,3 +609,39 @@ func TestUpdateAccountAPI(t *testing.T){
 	}
 }
 
+
+func TestGetAccountDisplayAPI(t *testing.T) {
+	store := db.NewMemStore()
+	user := createTestUser(t, store)
+	account := createTestAccount(t, store, user.Username, util.BRL, 123456)
+	merchant := createTestAccount(t, store, createTestUser(t, store).Username, util.BRL, 0)
+
+	// a hold lowers the available balance but not the balance
+	held, err := store.AuthorizeHoldTx(context.Background(), db.AuthorizeHoldTxParams{
+		FromAccountID: account.ID,
+		ToAccountID: merchant.ID,
+		Amount: 23456,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	account = held.FromAccount
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/accounts/%d", account.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	request.Header.Set("Accept-Language", "pt-BR,pt;q=0.9")
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got accountResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Equal(t, utcAccount(account), utcAccount(got.Account))
+	require.Equal(t, "R$\u00a01.234,56", got.BalanceDisplay)
+	require.Equal(t, "R$\u00a01.000,00", got.AvailableBalanceDisplay)
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+)
+
+type upsertTransferLimitRequest struct {
+	AccountID int64 `json:"account_id" binding:"omitempty,min=1"`
+	Tier string `json:"tier" binding:"omitempty,alphanum"`
+	MaxPerTransfer int64 `json:"max_per_transfer" binding:"min=0"`
+	MaxDailyAmount int64 `json:"max_daily_amount" binding:"min=0"`
+	MaxDailyCount int32 `json:"max_daily_count" binding:"min=0"`
+}
+
+type transferLimitResponse struct {
+	AccountID int64 `json:"account_id,omitempty"`
+	Tier string `json:"tier,omitempty"`
+	MaxPerTransfer int64 `json:"max_per_transfer"`
+	MaxDailyAmount int64 `json:"max_daily_amount"`
+	MaxDailyCount int32 `json:"max_daily_count"`
+}
+
+func newTransferLimitResponse(limit db.TransferLimit) transferLimitResponse {
+	return transferLimitResponse{
+		AccountID: limit.AccountID.Int64,
+		Tier: limit.Tier.String,
+		MaxPerTransfer: limit.MaxPerTransfer,
+		MaxDailyAmount: limit.MaxDailyAmount,
+		MaxDailyCount: limit.MaxDailyCount,
+	}
+}
+
+// sets the limits of an account or of a user tier; a zero limit means unlimited
+func (server *Server) upsertTransferLimit(ctx *gin.Context) error {
+	var req upsertTransferLimitRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	if (req.AccountID == 0) == (req.Tier == "") {
+		return newError(http.StatusBadRequest, codeValidationFailed, "exactly one of account_id and tier must be provided")
+	}
+
+	var limit db.TransferLimit
+	var err error
+
+	if req.AccountID != 0 {
+		limit, err = server.store.UpsertAccountTransferLimit(ctx, db.UpsertAccountTransferLimitParams{
+			AccountID: sql.NullInt64{Int64: req.AccountID, Valid: true},
+			MaxPerTransfer: req.MaxPerTransfer,
+			MaxDailyAmount: req.MaxDailyAmount,
+			MaxDailyCount: req.MaxDailyCount,
+		})
+	} else {
+		limit, err = server.store.UpsertTierTransferLimit(ctx, db.UpsertTierTransferLimitParams{
+			Tier: sql.NullString{String: req.Tier, Valid: true},
+			MaxPerTransfer: req.MaxPerTransfer,
+			MaxDailyAmount: req.MaxDailyAmount,
+			MaxDailyCount: req.MaxDailyCount,
+		})
+	}
+
+	if isForeignKeyViolation(err) {
+		return errAccountNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, newTransferLimitResponse(limit))
+	return nil
+}
+
+type getTransferLimitRequest struct {
+	AccountID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// returns the limits that apply to an account, either its own or those of its owner's tier
+func (server *Server) getTransferLimit(ctx *gin.Context) error {
+	var req getTransferLimitRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	limit, err := server.store.GetEffectiveTransferLimit(ctx, req.AccountID)
+
+	if err != nil {
+		return orNotFound(err, errTransferLimitNotFound)
+	}
+
+	ctx.JSON(http.StatusOK, newTransferLimitResponse(limit))
+	return nil
+}
+
+type updateUserTierRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	Tier string `json:"tier" binding:"required,alphanum"`
+}
+
+type userTierResponse struct {
+	Username string `json:"username"`
+	Tier string `json:"tier"`
+}
+
+func (server *Server) updateUserTier(ctx *gin.Context) error {
+	var req updateUserTierRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	user, err := server.store.UpdateUserTier(ctx, db.UpdateUserTierParams{
+		Username: req.Username,
+		Tier: req.Tier,
+	})
+
+	if err != nil {
+		return orNotFound(err, errUserNotFound)
+	}
+
+	ctx.JSON(http.StatusOK, userTierResponse{Username: user.Username, Tier: user.Tier})
+	return nil
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mateusribs/simple_bank/cli"
+	mockdb "github.com/mateusribs/simple_bank/db/mock"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/token"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomAdmin(t *testing.T) db.User {
+	admin, _ := randomUser(t)
+	admin.Role = util.AdminRole
+	return admin
+}
+
+// an admin made with the CLI, the only way to grant the role, can reach the admin API
+func TestAdminFromCLI(t *testing.T) {
+	store := db.NewMemStore()
+	server := newTestServer(t, store)
+
+	var stdout, stderr bytes.Buffer
+	support, err := cli.New(util.Config{}, store, strings.NewReader(""), &stdout, &stderr)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, support.Run(ctx, []string{"user", "create", "--username", "operator", "--full-name", "Operator", "--email", "operator@email.com", "--password", "Secret#2024"}))
+
+	upsertLimit := func() *httptest.ResponseRecorder {
+		data, err := json.Marshal(gin.H{"tier": "gold", "max_daily_amount": 1000})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/admin/transfer_limits", bytes.NewReader(data))
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "operator", time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusForbidden, upsertLimit().Code)
+
+	require.NoError(t, support.Run(ctx, []string{"user", "set-role", "--username", "operator", "--role", util.AdminRole}))
+	require.Equal(t, http.StatusOK, upsertLimit().Code)
+}
+
+func TestUpsertTransferLimitAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	customer, _ := randomUser(t)
+	customer.Role = util.CustomerRole
+
+	limit := db.TransferLimit{
+		ID: util.RandomInt(1, 1000),
+		AccountID: sql.NullInt64{Int64: util.RandomInt(1, 1000), Valid: true},
+		MaxPerTransfer: 500,
+		MaxDailyAmount: 1000,
+		MaxDailyCount: 5,
+	}
+
+	testCases := []struct{
+		name string
+		body gin.H
+		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "AccountLimit",
+			body: gin.H{
+				"account_id": limit.AccountID.Int64,
+				"max_per_transfer": limit.MaxPerTransfer,
+				"max_daily_amount": limit.MaxDailyAmount,
+				"max_daily_count": limit.MaxDailyCount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+
+				arg := db.UpsertAccountTransferLimitParams{
+					AccountID: limit.AccountID,
+					MaxPerTransfer: limit.MaxPerTransfer,
+					MaxDailyAmount: limit.MaxDailyAmount,
+					MaxDailyCount: limit.MaxDailyCount,
+				}
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Eq(arg)).Times(1).Return(limit, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got transferLimitResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, newTransferLimitResponse(limit), got)
+			},
+		},
+		{
+			name: "TierLimit",
+			body: gin.H{
+				"tier": "gold",
+				"max_daily_amount": limit.MaxDailyAmount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+
+				arg := db.UpsertTierTransferLimitParams{
+					Tier: sql.NullString{String: "gold", Valid: true},
+					MaxDailyAmount: limit.MaxDailyAmount,
+				}
+				store.EXPECT().UpsertTierTransferLimit(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferLimit{Tier: arg.Tier}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "AccountAndTier",
+			body: gin.H{
+				"account_id": limit.AccountID.Int64,
+				"tier": "gold",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpsertTierTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			body: gin.H{
+				"account_id": limit.AccountID.Int64,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, customer.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(customer, nil)
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{
+				"account_id": limit.AccountID.Int64,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/admin/transfer_limits"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
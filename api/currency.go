@@ -0,0 +1,85 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/money"
+)
+
+type currencyResponse struct {
+	Code string `json:"code"`
+	Exponent int32 `json:"exponent"`
+	Symbol string `json:"symbol"`
+	Name string `json:"name"`
+}
+
+// lists the currencies accounts can be opened in
+func (server *Server) listCurrencies(ctx *gin.Context) {
+	currencies := server.currencies.Enabled()
+
+	rsp := make([]currencyResponse, len(currencies))
+
+	for i, currency := range currencies {
+		metadata, _ := money.Lookup(currency.Code)
+
+		rsp[i] = currencyResponse{
+			Code: currency.Code,
+			Exponent: currency.Exponent,
+			Symbol: metadata.Symbol,
+			Name: metadata.Name,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type upsertCurrencyRequest struct {
+	Code string `json:"code" binding:"required,len=3,alpha"`
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// adds a currency to the catalogue or enables and disables it; the exponent comes from ISO 4217
+func (server *Server) upsertCurrency(ctx *gin.Context) error {
+	var req upsertCurrencyRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	metadata, ok := money.Lookup(req.Code)
+
+	if !ok {
+		return fmt.Errorf("%w: %s", money.ErrUnknownCurrency, strings.ToUpper(req.Code))
+	}
+
+	currency, err := server.store.UpsertCurrency(ctx, db.UpsertCurrencyParams{
+		Code: metadata.Code,
+		Exponent: int32(metadata.Exponent),
+		Enabled: *req.Enabled,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	server.currencies.Set(currency)
+
+	ctx.JSON(http.StatusOK, currency)
+	return nil
+}
+
+// lists every currency of the catalogue, disabled ones included
+func (server *Server) listAllCurrencies(ctx *gin.Context) error {
+	currencies, err := server.store.ListCurrencies(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, currencies)
+	return nil
+}
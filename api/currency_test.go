@@ -0,0 +1,253 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	mockdb "github.com/mateusribs/simple_bank/db/mock"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func requireCurrencyCodes(t *testing.T, recorder *httptest.ResponseRecorder, codes ...string) {
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []currencyResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+
+	gotCodes := make([]string, len(got))
+
+	for i, currency := range got {
+		gotCodes[i] = currency.Code
+	}
+
+	require.Equal(t, codes, gotCodes)
+}
+
+func TestListCurrenciesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	// the seeded currencies until the catalogue is refreshed
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/currencies", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	requireCurrencyCodes(t, recorder, util.BRL, util.EUR, util.USD)
+
+	var got []currencyResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Equal(t, currencyResponse{Code: util.BRL, Exponent: 2, Symbol: "R$", Name: "Brazilian Real"}, got[0])
+
+	store.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return([]db.Currency{
+		{Code: "JPY", Exponent: 0, Enabled: true},
+		{Code: util.USD, Exponent: 2, Enabled: true},
+		{Code: util.EUR, Exponent: 2, Enabled: false},
+	}, nil)
+	require.NoError(t, server.currencies.Refresh(context.Background(), store))
+
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	requireCurrencyCodes(t, recorder, "JPY", util.USD)
+
+	// a failed refresh keeps the last catalogue
+	store.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+	require.Error(t, server.currencies.Refresh(context.Background(), store))
+	require.True(t, server.currencies.IsEnabled("JPY"))
+	require.False(t, server.currencies.IsEnabled(util.EUR))
+}
+
+func TestUpsertCurrencyAPI(t *testing.T) {
+	admin := randomAdmin(t)
+
+	testCases := []struct{
+		name string
+		body gin.H
+		buildStubs func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Enable",
+			body: gin.H{
+				"code": "jpy",
+				"enabled": true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+
+				arg := db.UpsertCurrencyParams{Code: "JPY", Exponent: 0, Enabled: true}
+				store.EXPECT().UpsertCurrency(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.Currency{Code: "JPY", Exponent: 0, Enabled: true}, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.True(t, server.currencies.IsEnabled("JPY"))
+			},
+		},
+		{
+			name: "Disable",
+			body: gin.H{
+				"code": util.USD,
+				"enabled": false,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+
+				arg := db.UpsertCurrencyParams{Code: util.USD, Exponent: 2, Enabled: false}
+				store.EXPECT().UpsertCurrency(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.Currency{Code: util.USD, Exponent: 2, Enabled: false}, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.False(t, server.currencies.IsEnabled(util.USD))
+			},
+		},
+		{
+			name: "UnknownCurrency",
+			body: gin.H{
+				"code": "XYZ",
+				"enabled": true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().UpsertCurrency(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MissingEnabled",
+			body: gin.H{
+				"code": "JPY",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().UpsertCurrency(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"code": "JPY",
+				"enabled": true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().UpsertCurrency(gomock.Any(), gomock.Any()).Times(1).Return(db.Currency{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.False(t, server.currencies.IsEnabled("JPY"))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/admin/currencies"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
+
+func TestCurrencyValidatorFollowsCatalog(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	createAccount := func(currency string) int {
+		data, err := json.Marshal(gin.H{"currency": currency})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+
+		return recorder.Code
+	}
+
+	store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+	require.Equal(t, http.StatusBadRequest, createAccount("JPY"))
+
+	server.currencies.Set(db.Currency{Code: "JPY", Exponent: 0, Enabled: true})
+
+	store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{Currency: "JPY"}, nil)
+	require.Equal(t, http.StatusOK, createAccount("JPY"))
+}
+
+// servers in one process, such as parallel tests, each check currencies against their own catalogue
+func TestCurrencyValidatorPerServer(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	withJPY := newTestServer(t, store)
+	withJPY.currencies.Set(db.Currency{Code: "JPY", Exponent: 0, Enabled: true})
+	withoutJPY := newTestServer(t, store)
+
+	createAccount := func(server *Server) int {
+		data, err := json.Marshal(gin.H{"currency": "JPY"})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+
+		return recorder.Code
+	}
+
+	store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{Currency: "JPY"}, nil)
+	require.Equal(t, http.StatusOK, createAccount(withJPY))
+	require.Equal(t, http.StatusBadRequest, createAccount(withoutJPY))
+}
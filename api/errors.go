@@ -0,0 +1,345 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mateusribs/simple_bank/auth"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/money"
+	"github.com/mateusribs/simple_bank/util"
+)
+
+// stable codes clients can switch on; the messages may change
+const (
+	codeInvalidRequest = "INVALID_REQUEST"
+	codeValidationFailed = "VALIDATION_FAILED"
+	codePasswordPolicy = "PASSWORD_POLICY"
+	codeUnauthenticated = "UNAUTHENTICATED"
+	codeInvalidCredentials = "INVALID_CREDENTIALS"
+	codeInvalidSession = "INVALID_SESSION"
+	codeTOTPNotEnrolled = "TOTP_NOT_ENROLLED"
+	codeElevationRequired = "ELEVATION_REQUIRED"
+	codeAdminRequired = "ADMIN_REQUIRED"
+	codeNotOwner = "NOT_OWNER"
+	codeRouteNotFound = "ROUTE_NOT_FOUND"
+	codeUserNotFound = "USER_NOT_FOUND"
+	codeAccountNotFound = "ACCOUNT_NOT_FOUND"
+	codeSessionNotFound = "SESSION_NOT_FOUND"
+	codeHoldNotFound = "HOLD_NOT_FOUND"
+	codeChallengeNotFound = "CHALLENGE_NOT_FOUND"
+	codeTransferLimitNotFound = "TRANSFER_LIMIT_NOT_FOUND"
+	codeAlreadyExists = "ALREADY_EXISTS"
+	codeReferenceNotFound = "REFERENCE_NOT_FOUND"
+	codeUnknownCurrency = "UNKNOWN_CURRENCY"
+	codeCurrencyMismatch = "CURRENCY_MISMATCH"
+	codeInvalidAmount = "INVALID_AMOUNT"
+	codeAmountOverflow = "AMOUNT_OVERFLOW"
+	codeInsufficientFunds = "INSUFFICIENT_FUNDS"
+	codeTransferLimitExceeded = "TRANSFER_LIMIT_EXCEEDED"
+	codeCaptureExceedsHold = "CAPTURE_EXCEEDS_HOLD"
+	codeHoldNotAuthorized = "HOLD_NOT_AUTHORIZED"
+	codeHoldExpired = "HOLD_EXPIRED"
+	codeChallengeCompleted = "CHALLENGE_COMPLETED"
+	codeChallengeExpired = "CHALLENGE_EXPIRED"
+	codeAccountFrozen = "ACCOUNT_FROZEN"
+	codeInternal = "INTERNAL"
+)
+
+// every code above, for the OpenAPI document
+var errorCodes = []string{
+	codeInvalidRequest, codeValidationFailed, codePasswordPolicy,
+	codeUnauthenticated, codeInvalidCredentials, codeInvalidSession, codeTOTPNotEnrolled,
+	codeElevationRequired, codeAdminRequired, codeNotOwner,
+	codeRouteNotFound, codeUserNotFound, codeAccountNotFound, codeSessionNotFound,
+	codeHoldNotFound, codeChallengeNotFound, codeTransferLimitNotFound,
+	codeAlreadyExists, codeReferenceNotFound,
+	codeUnknownCurrency, codeCurrencyMismatch, codeInvalidAmount, codeAmountOverflow,
+	codeInsufficientFunds, codeTransferLimitExceeded, codeCaptureExceedsHold,
+	codeHoldNotAuthorized, codeHoldExpired, codeChallengeCompleted, codeChallengeExpired,
+	codeAccountFrozen, codeInternal,
+}
+
+var (
+	errUserNotFound = newError(http.StatusNotFound, codeUserNotFound, "user not found")
+	errAccountNotFound = newError(http.StatusNotFound, codeAccountNotFound, "account not found")
+	errSessionNotFound = newError(http.StatusNotFound, codeSessionNotFound, "session not found")
+	errHoldNotFound = newError(http.StatusNotFound, codeHoldNotFound, "hold not found")
+	errChallengeNotFound = newError(http.StatusNotFound, codeChallengeNotFound, "transfer challenge not found")
+	errTransferLimitNotFound = newError(http.StatusNotFound, codeTransferLimitNotFound, "no transfer limit applies to the account")
+)
+
+// an error as the client sees it
+type apiError struct {
+	Status int
+	Code string
+	Message string
+	Details []fieldError
+	Limit *db.TransferLimitError
+	// logged, never sent to the client
+	cause error
+}
+
+// a binding rule a request field broke
+type fieldError struct {
+	Field string `json:"field"`
+	Rule string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// body of every error response
+type errorResponse struct {
+	Error errorBody `json:"error" binding:"required"`
+}
+
+type errorBody struct {
+	Code string `json:"code" binding:"required"`
+	Message string `json:"message" binding:"required"`
+	Details []fieldError `json:"details,omitempty"`
+	// set on TRANSFER_LIMIT_EXCEEDED
+	Limit *db.TransferLimitError `json:"limit,omitempty"`
+	RequestID string `json:"request_id" binding:"required"`
+}
+
+func newError(status int, code string, message string) *apiError {
+	return &apiError{Status: status, Code: code, Message: message}
+}
+
+func (e *apiError) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.cause)
+	}
+	return e.Message
+}
+
+func (e *apiError) Unwrap() error {
+	return e.cause
+}
+
+// replaces sql.ErrNoRows with the not found error of the resource
+func orNotFound(err error, notFound *apiError) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	}
+	return err
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation
+}
+
+// turns the error of ShouldBind* into a validation error listing every broken rule
+func invalidRequest(err error) *apiError {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		apiErr := newError(http.StatusBadRequest, codeValidationFailed, "request validation failed")
+
+		for _, fieldErr := range validationErrs {
+			apiErr.Details = append(apiErr.Details, fieldError{
+				Field: fieldErr.Field(),
+				Rule: fieldErr.Tag(),
+				Message: ruleMessage(fieldErr),
+			})
+		}
+
+		return apiErr
+	case errors.As(err, &typeErr):
+		apiErr := newError(http.StatusBadRequest, codeValidationFailed, "request validation failed")
+		apiErr.Details = []fieldError{{
+			Field: typeErr.Field,
+			Rule: "type",
+			Message: fmt.Sprintf("must be a %s", typeErr.Type.Kind()),
+		}}
+		return apiErr
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return newError(http.StatusBadRequest, codeInvalidRequest, "request body is not valid JSON")
+	}
+
+	return &apiError{Status: http.StatusBadRequest, Code: codeInvalidRequest, Message: "request is malformed", cause: err}
+}
+
+func ruleMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	isString := fieldErr.Kind() == reflect.String
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return fmt.Sprintf("is required when %s is missing", snakeCase(param))
+	case "min":
+		if isString {
+			return fmt.Sprintf("must be at least %s characters long", param)
+		}
+		return fmt.Sprintf("must be at least %s", param)
+	case "max":
+		if isString {
+			return fmt.Sprintf("must be at most %s characters long", param)
+		}
+		return fmt.Sprintf("must be at most %s", param)
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", param)
+	case "len":
+		return fmt.Sprintf("must be exactly %s characters long", param)
+	case "email":
+		return "must be a valid email address"
+	case "uuid":
+		return "must be a UUID"
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "alpha":
+		return "must contain only letters"
+	case "numeric":
+		return "must contain only digits"
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(param), ", "))
+	case "currency":
+		return "must be an ISO 4217 currency code"
+	}
+
+	return "is invalid"
+}
+
+// the wire name of a field referenced by a rule parameter, e.g. TOTPCode becomes totp_code
+func snakeCase(goName string) string {
+	runes := []rune(goName)
+	var builder strings.Builder
+
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if prevLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+				builder.WriteByte('_')
+			}
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+
+	return builder.String()
+}
+
+// maps domain and database errors to their status and code; unknown errors become a 500 that hides the cause
+func toAPIError(err error) *apiError {
+	var apiErr *apiError
+
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var limitErr *db.TransferLimitError
+
+	if errors.As(err, &limitErr) {
+		apiErr := newError(http.StatusUnprocessableEntity, codeTransferLimitExceeded, limitErr.Error())
+		apiErr.Limit = limitErr
+		return apiErr
+	}
+
+	var policyErr *util.PasswordPolicyError
+
+	if errors.As(err, &policyErr) {
+		apiErr := newError(http.StatusBadRequest, codePasswordPolicy, "password does not meet the policy")
+
+		for _, violation := range policyErr.Violations {
+			apiErr.Details = append(apiErr.Details, fieldError{Field: "password", Rule: "password", Message: violation})
+		}
+
+		return apiErr
+	}
+
+	for _, domainErr := range domainErrors {
+		if errors.Is(err, domainErr.err) {
+			return newError(domainErr.status, domainErr.code, err.Error())
+		}
+	}
+
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.UniqueViolation:
+			return &apiError{Status: http.StatusForbidden, Code: codeAlreadyExists, Message: "a resource with the same unique fields already exists", cause: err}
+		case pgerrcode.ForeignKeyViolation:
+			return &apiError{Status: http.StatusForbidden, Code: codeReferenceNotFound, Message: "a referenced resource does not exist", cause: err}
+		}
+	}
+
+	return &apiError{Status: http.StatusInternalServerError, Code: codeInternal, Message: "internal server error", cause: err}
+}
+
+// sentinel errors whose messages are safe to show
+var domainErrors = []struct{
+	err error
+	status int
+	code string
+}{
+	{money.ErrUnknownCurrency, http.StatusBadRequest, codeUnknownCurrency},
+	{money.ErrCurrencyMismatch, http.StatusBadRequest, codeCurrencyMismatch},
+	{money.ErrInvalidAmount, http.StatusBadRequest, codeInvalidAmount},
+	{money.ErrOverflow, http.StatusUnprocessableEntity, codeAmountOverflow},
+	{db.ErrInsufficientFunds, http.StatusUnprocessableEntity, codeInsufficientFunds},
+	{db.ErrCaptureExceedsHold, http.StatusUnprocessableEntity, codeCaptureExceedsHold},
+	{db.ErrHoldNotAuthorized, http.StatusConflict, codeHoldNotAuthorized},
+	{db.ErrHoldExpired, http.StatusGone, codeHoldExpired},
+	{db.ErrChallengeCompleted, http.StatusConflict, codeChallengeCompleted},
+	{db.ErrChallengeExpired, http.StatusGone, codeChallengeExpired},
+	{db.ErrAccountFrozen, http.StatusForbidden, codeAccountFrozen},
+	{auth.ErrTOTPNotEnrolled, http.StatusBadRequest, codeTOTPNotEnrolled},
+	{auth.ErrInvalidTOTPCode, http.StatusUnauthorized, codeInvalidCredentials},
+}
+
+// handlers return their error instead of writing it; errorMiddleware renders it
+type handlerFunc func(ctx *gin.Context) error
+
+func handle(handler handlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := handler(ctx); err != nil {
+			ctx.Error(err)
+		}
+	}
+}
+
+// aborts the chain with the error, for middlewares
+func abortWithError(ctx *gin.Context, err error) {
+	ctx.Error(err)
+	ctx.Abort()
+}
+
+// writes the last error of the request in the common envelope; loggerMiddleware logs the cause of 500s
+func errorMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+
+		apiErr := toAPIError(ctx.Errors.Last().Err)
+		requestID := ctx.GetString(requestIDKey)
+
+		ctx.JSON(apiErr.Status, errorResponse{Error: errorBody{
+			Code: apiErr.Code,
+			Message: apiErr.Message,
+			Details: apiErr.Details,
+			Limit: apiErr.Limit,
+			RequestID: requestID,
+		}})
+	}
+}
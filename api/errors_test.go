@@ -0,0 +1,201 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	mockdb "github.com/mateusribs/simple_bank/db/mock"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/money"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func requireErrorResponse(t *testing.T, recorder *httptest.ResponseRecorder, status int, code string) errorBody {
+	require.Equal(t, status, recorder.Code)
+
+	var body errorResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Equal(t, code, body.Error.Code)
+	require.NotEmpty(t, body.Error.Message)
+	require.Equal(t, recorder.Header().Get(requestIDHeaderKey), body.Error.RequestID)
+
+	return body.Error
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	testCases := []struct{
+		name string
+		method string
+		url string
+		body string
+		requestID string
+		buildStubs func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ValidationDetails",
+			method: http.MethodPost,
+			url: "/accounts",
+			body: `{"currency": "XXX", "product": "not valid"}`,
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				body := requireErrorResponse(t, recorder, http.StatusBadRequest, codeValidationFailed)
+				require.ElementsMatch(t, []fieldError{
+					{Field: "currency", Rule: "currency", Message: "must be an ISO 4217 currency code"},
+					{Field: "product", Rule: "alphanum", Message: "must contain only letters and digits"},
+				}, body.Details)
+			},
+		},
+		{
+			name: "QueryFieldNames",
+			method: http.MethodGet,
+			url: "/accounts?page_id=0&page_size=20",
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				body := requireErrorResponse(t, recorder, http.StatusBadRequest, codeValidationFailed)
+				require.Len(t, body.Details, 2)
+				require.Equal(t, "page_id", body.Details[0].Field)
+				require.Equal(t, "page_size", body.Details[1].Field)
+				require.Equal(t, "must be at most 10", body.Details[1].Message)
+			},
+		},
+		{
+			name: "MalformedJSON",
+			method: http.MethodPost,
+			url: "/accounts",
+			body: `{"currency": `,
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireErrorResponse(t, recorder, http.StatusBadRequest, codeInvalidRequest)
+			},
+		},
+		{
+			name: "ClientRequestID",
+			method: http.MethodGet,
+			url: "/accounts/1",
+			requestID: "client-request-1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, "client-request-1", recorder.Header().Get(requestIDHeaderKey))
+				requireErrorResponse(t, recorder, http.StatusNotFound, codeAccountNotFound)
+			},
+		},
+		{
+			name: "InternalErrorHidesCause",
+			method: http.MethodGet,
+			url: "/accounts/1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				body := requireErrorResponse(t, recorder, http.StatusInternalServerError, codeInternal)
+				require.NotEmpty(t, body.RequestID)
+				require.NotContains(t, recorder.Body.String(), sql.ErrConnDone.Error())
+			},
+		},
+		{
+			name: "UniqueViolationHidesConstraint",
+			method: http.MethodPost,
+			url: "/accounts",
+			body: `{"currency": "USD"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				pqErr := &pgconn.PgError{Code: "23505", Message: `duplicate key value violates unique constraint "owner_currency_key"`}
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, pqErr)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireErrorResponse(t, recorder, http.StatusForbidden, codeAlreadyExists)
+				require.NotContains(t, recorder.Body.String(), "owner_currency_key")
+			},
+		},
+		{
+			name: "RouteNotFound",
+			method: http.MethodGet,
+			url: "/no/such/route",
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireErrorResponse(t, recorder, http.StatusNotFound, codeRouteNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+
+			if tc.requestID != "" {
+				request.Header.Set(requestIDHeaderKey, tc.requestID)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestToAPIError(t *testing.T) {
+	testCases := []struct{
+		err error
+		status int
+		code string
+	}{
+		{fmt.Errorf("transfer: %w", money.ErrCurrencyMismatch), http.StatusBadRequest, codeCurrencyMismatch},
+		{fmt.Errorf("%w: XYZ", money.ErrUnknownCurrency), http.StatusBadRequest, codeUnknownCurrency},
+		{money.ErrOverflow, http.StatusUnprocessableEntity, codeAmountOverflow},
+		{db.ErrInsufficientFunds, http.StatusUnprocessableEntity, codeInsufficientFunds},
+		{db.ErrHoldNotAuthorized, http.StatusConflict, codeHoldNotAuthorized},
+		{db.ErrHoldExpired, http.StatusGone, codeHoldExpired},
+		{db.ErrChallengeCompleted, http.StatusConflict, codeChallengeCompleted},
+		{fmt.Errorf("%w: account [1]", db.ErrAccountFrozen), http.StatusForbidden, codeAccountFrozen},
+		{sql.ErrNoRows, http.StatusInternalServerError, codeInternal},
+		{&pgconn.PgError{Code: "23503"}, http.StatusForbidden, codeReferenceNotFound},
+		{errAccountNotFound, http.StatusNotFound, codeAccountNotFound},
+		{sql.ErrConnDone, http.StatusInternalServerError, codeInternal},
+	}
+
+	for _, tc := range testCases {
+		apiErr := toAPIError(tc.err)
+		require.Equal(t, tc.status, apiErr.Status, tc.err.Error())
+		require.Equal(t, tc.code, apiErr.Code, tc.err.Error())
+	}
+}
+
+func TestRequestIDTooLong(t *testing.T) {
+	server := newTestServer(t, nil)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/no/such/route", nil)
+	require.NoError(t, err)
+
+	tooLong := string(bytes.Repeat([]byte("a"), maxRequestIDLength+1))
+	request.Header.Set(requestIDHeaderKey, tooLong)
+
+	server.router.ServeHTTP(recorder, request)
+
+	requestID := recorder.Header().Get(requestIDHeaderKey)
+	require.NotEmpty(t, requestID)
+	require.NotEqual(t, tooLong, requestID)
+}
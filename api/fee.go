@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/money"
+)
+
+type quoteTransferRequest struct {
+	Amount int64 `json:"amount" binding:"required,gt=0"`
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+type quoteTransferResponse struct {
+	db.FeeBreakdown
+	TotalDebit int64 `json:"total_debit"`
+	FeeDisplay string `json:"fee_display"`
+	TotalDebitDisplay string `json:"total_debit_display"`
+}
+
+// previews the fee of a transfer and how much the payer will be debited in total
+func (server *Server) quoteTransfer(ctx *gin.Context) error {
+	var req quoteTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	if err := server.checkCurrency(req.Currency); err != nil {
+		return err
+	}
+
+	fee, err := db.QuoteFee(ctx, server.store, req.Currency, req.Amount)
+
+	if err != nil {
+		return err
+	}
+
+	totalDebit, err := money.Money{Amount: req.Amount, Currency: req.Currency}.Add(
+		money.Money{Amount: fee.Total, Currency: req.Currency},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	locale := ctx.GetHeader("Accept-Language")
+
+	ctx.JSON(http.StatusOK, quoteTransferResponse{
+		FeeBreakdown: fee,
+		TotalDebit: totalDebit.Amount,
+		FeeDisplay: money.Money{Amount: fee.Total, Currency: req.Currency}.Format(locale),
+		TotalDebitDisplay: totalDebit.Format(locale),
+	})
+	return nil
+}
+
+type createFeeScheduleRequest struct {
+	Currency string `json:"currency" binding:"required,currency"`
+	MinAmount int64 `json:"min_amount" binding:"min=0"`
+	FlatFee int64 `json:"flat_fee" binding:"min=0"`
+	PercentageBps int32 `json:"percentage_bps" binding:"min=0,max=10000"`
+	RevenueAccountID int64 `json:"revenue_account_id" binding:"required,min=1"`
+}
+
+// adds a fee tier that applies to transfers of the currency from min_amount up to the next tier
+func (server *Server) createFeeSchedule(ctx *gin.Context) error {
+	var req createFeeScheduleRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	if err := server.checkCurrency(req.Currency); err != nil {
+		return err
+	}
+
+	if _, err := server.validAccount(ctx, req.RevenueAccountID, req.Currency); err != nil {
+		return err
+	}
+
+	schedule, err := server.store.CreateFeeSchedule(ctx, db.CreateFeeScheduleParams{
+		Currency: req.Currency,
+		MinAmount: req.MinAmount,
+		FlatFee: req.FlatFee,
+		PercentageBps: req.PercentageBps,
+		RevenueAccountID: req.RevenueAccountID,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+	return nil
+}
+
+func (server *Server) listFeeSchedules(ctx *gin.Context) error {
+	schedules, err := server.store.ListFeeSchedules(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, schedules)
+	return nil
+}
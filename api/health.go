@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/logger"
+)
+
+const (
+	healthOK = "ok"
+	healthUnavailable = "unavailable"
+)
+
+// longest the readiness checks may take together, well under the usual probe timeout
+const readinessTimeout = 2 * time.Second
+
+type checkResult struct {
+	Status string `json:"status"`
+	Error string `json:"error,omitempty"`
+}
+
+type healthResponse struct {
+	Status string `json:"status"`
+	Checks map[string]checkResult `json:"checks,omitempty"`
+}
+
+// a dependency the server needs to serve requests
+type readinessCheck struct {
+	name string
+	check func(ctx context.Context) error
+}
+
+// answers as long as the process can serve HTTP; it stays up while draining so the orchestrator does not kill in-flight requests
+func (server *Server) healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, healthResponse{Status: healthOK})
+}
+
+// reports whether the server should receive traffic, with the status of every dependency
+func (server *Server) readyz(ctx *gin.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	rsp := healthResponse{Status: healthOK, Checks: map[string]checkResult{}}
+
+	if server.draining.Load() {
+		rsp.Status = healthUnavailable
+		rsp.Checks["draining"] = checkResult{Status: healthUnavailable, Error: "server is shutting down"}
+	}
+
+	for _, readiness := range server.readinessChecks() {
+		if err := readiness.check(checkCtx); err != nil {
+			logger.FromContext(ctx).Warn().Err(err).Str("check", readiness.name).Msg("readiness check failed")
+
+			rsp.Status = healthUnavailable
+			rsp.Checks[readiness.name] = checkResult{Status: healthUnavailable, Error: publicCheckError(err)}
+			continue
+		}
+
+		rsp.Checks[readiness.name] = checkResult{Status: healthOK}
+	}
+
+	status := http.StatusOK
+
+	if rsp.Status != healthOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	ctx.JSON(status, rsp)
+}
+
+func (server *Server) readinessChecks() []readinessCheck {
+	return []readinessCheck{
+		{name: "database", check: server.store.Ping},
+		{name: "migrations", check: server.checkMigrations},
+		{name: "token_maker", check: server.checkTokenMaker},
+	}
+}
+
+// an error whose message is safe to show on the public probe
+type checkError struct {
+	message string
+}
+
+func (e *checkError) Error() string {
+	return e.message
+}
+
+// the message of errors the checks produce themselves; dependency errors may reveal hosts or credentials and stay in the log
+func publicCheckError(err error) string {
+	var checkErr *checkError
+
+	if errors.As(err, &checkErr) {
+		return checkErr.message
+	}
+
+	return "check failed"
+}
+
+// fails on a schema older than the server expects or left dirty; a newer schema only logs a warning, since
+// migrations only add to the schema and an instance of the previous release keeps serving during a rollout
+func (server *Server) checkMigrations(ctx context.Context) error {
+	version, dirty, err := server.store.MigrationVersion(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return &checkError{fmt.Sprintf("migration %d failed and left the schema dirty", version)}
+	}
+
+	if version < db.SchemaVersion {
+		return &checkError{fmt.Sprintf("schema is at version %d, the server expects %d", version, db.SchemaVersion)}
+	}
+
+	if version > db.SchemaVersion {
+		logger.FromContext(ctx).Warn().Int64("version", version).Int64("expected", int64(db.SchemaVersion)).Msg("schema is newer than the server")
+	}
+
+	return nil
+}
+
+// issues and verifies a short-lived token, which fails if the key was not loaded
+func (server *Server) checkTokenMaker(ctx context.Context) error {
+	if server.tokenMaker == nil {
+		return &checkError{"token maker is not loaded"}
+	}
+
+	token, _, err := server.tokenMaker.CreateToken("readiness", time.Minute)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = server.tokenMaker.VerifyToken(token)
+
+	return err
+}
+
+// fails the readiness probe from now on, so the orchestrator stops routing new requests before the server stops
+func (server *Server) StartDraining() {
+	server.draining.Store(true)
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/mateusribs/simple_bank/db/mock"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/token"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHealthz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	server := newTestServer(t, store)
+	server.StartDraining()
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp healthResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.Equal(t, healthOK, rsp.Status)
+}
+
+func TestReadyz(t *testing.T) {
+	testCases := []struct {
+		name string
+		draining bool
+		buildStubs func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(db.SchemaVersion), false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, healthOK, rsp.Status)
+				require.Equal(t, map[string]checkResult{
+					"database": {Status: healthOK},
+					"migrations": {Status: healthOK},
+					"token_maker": {Status: healthOK},
+				}, rsp.Checks)
+			},
+		},
+		{
+			name: "DatabaseDown",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(errors.New("dial tcp 10.0.0.1:5432: connection refused"))
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(0), false, errors.New("connection refused"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				require.Equal(t, healthUnavailable, rsp.Status)
+				require.Equal(t, checkResult{Status: healthUnavailable, Error: "check failed"}, rsp.Checks["database"])
+				require.NotContains(t, recorder.Body.String(), "10.0.0.1")
+				require.Equal(t, healthOK, rsp.Checks["token_maker"].Status)
+			},
+		},
+		{
+			name: "SchemaBehind",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(db.SchemaVersion-1), false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				require.Equal(t, healthOK, rsp.Checks["database"].Status)
+				require.Equal(t, healthUnavailable, rsp.Checks["migrations"].Status)
+				require.Contains(t, rsp.Checks["migrations"].Error, "the server expects")
+			},
+		},
+		{
+			// a newer release migrated the database while this one still serves
+			name: "SchemaAhead",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(db.SchemaVersion+1), false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, healthOK, rsp.Checks["migrations"].Status)
+			},
+		},
+		{
+			name: "DirtySchema",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(db.SchemaVersion), true, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				require.Contains(t, rsp.Checks["migrations"].Error, "dirty")
+			},
+		},
+		{
+			name: "Draining",
+			draining: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(db.SchemaVersion), false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, rsp healthResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				require.Equal(t, healthUnavailable, rsp.Status)
+				require.Equal(t, healthUnavailable, rsp.Checks["draining"].Status)
+				require.Equal(t, healthOK, rsp.Checks["database"].Status)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			if tc.draining {
+				server.StartDraining()
+			}
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+
+			var rsp healthResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+			tc.checkResponse(t, recorder, rsp)
+		})
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	config := util.Config{
+		TokenSymmetricKey: util.RandomString(32),
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	server, err := NewServer(config, store, tokenMaker, db.NewCurrencyCatalog())
+	require.NoError(t, err)
+
+	started := make(chan error, 1)
+
+	go func() {
+		started <- server.Start("127.0.0.1:0")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, server.Shutdown(ctx))
+	require.NoError(t, <-started)
+	require.True(t, server.draining.Load())
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/token"
+)
+
+type createHoldRequest struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID int64 `json:"to_account_id" binding:"required,min=1"`
+	Amount int64 `json:"amount" binding:"required,gt=0"`
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+// authorizes a payment by holding the amount on the payer's account until it is captured, voided or expires
+func (server *Server) createHold(ctx *gin.Context) error {
+	var req createHoldRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	if err := server.checkCurrency(req.Currency); err != nil {
+		return err
+	}
+
+	fromAccount, err := server.validAccount(ctx, req.FromAccountID, req.Currency)
+
+	if err != nil {
+		return err
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if fromAccount.Owner != authPayload.Username {
+		return newError(http.StatusUnauthorized, codeNotOwner, "from account does not belong to the authenticated user")
+	}
+
+	if _, err := server.validAccount(ctx, req.ToAccountID, req.Currency); err != nil {
+		return err
+	}
+
+	// the receiving owner captures a hold without a second factor, so large ones need it up front
+	if server.requiresStepUp(req.Amount) && !authPayload.Elevated {
+		return newError(http.StatusForbidden, codeElevationRequired, "holds of this amount need an elevated token, re-authenticate at /tokens/step_up")
+	}
+
+	result, err := server.store.AuthorizeHoldTx(ctx, db.AuthorizeHoldTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID: req.ToAccountID,
+		Amount: req.Amount,
+		ExpiresAt: time.Now().Add(server.config.HoldDuration),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, result)
+	return nil
+}
+
+type holdURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getHold(ctx *gin.Context) error {
+	var uri holdURI
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		return invalidRequest(err)
+	}
+
+	hold, err := server.validHold(ctx, uri.ID, false)
+
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+	return nil
+}
+
+type captureHoldRequest struct {
+	Amount int64 `json:"amount" binding:"min=0"`
+}
+
+// moves the captured amount, the full hold by default, to the receiving account
+func (server *Server) captureHold(ctx *gin.Context) error {
+	var uri holdURI
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		return invalidRequest(err)
+	}
+
+	var req captureHoldRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	if _, err := server.validHold(ctx, uri.ID, true); err != nil {
+		return err
+	}
+
+	result, err := server.store.CaptureHoldTx(ctx, db.CaptureHoldTxParams{
+		HoldID: uri.ID,
+		Amount: req.Amount,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, result)
+	return nil
+}
+
+// releases the held amount back to the payer
+func (server *Server) voidHold(ctx *gin.Context) error {
+	var uri holdURI
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		return invalidRequest(err)
+	}
+
+	if _, err := server.validHold(ctx, uri.ID, false); err != nil {
+		return err
+	}
+
+	hold, err := server.store.VoidHoldTx(ctx, uri.ID)
+
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+	return nil
+}
+
+// loads the hold and checks the authenticated user is a party to it;
+// only the owner of the receiving account may capture
+func (server *Server) validHold(ctx *gin.Context, holdID int64, capture bool) (db.Hold, error) {
+	hold, err := server.store.GetHold(ctx, holdID)
+
+	if err != nil {
+		return hold, orNotFound(err, errHoldNotFound)
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	toAccount, err := server.store.GetAccount(ctx, hold.ToAccountID)
+
+	if err != nil {
+		return hold, err
+	}
+
+	if toAccount.Owner == authPayload.Username {
+		return hold, nil
+	}
+
+	if !capture {
+		fromAccount, err := server.store.GetAccount(ctx, hold.FromAccountID)
+
+		if err != nil {
+			return hold, err
+		}
+
+		if fromAccount.Owner == authPayload.Username {
+			return hold, nil
+		}
+	}
+
+	return hold, newError(http.StatusUnauthorized, codeNotOwner, "hold does not belong to the authenticated user")
+}
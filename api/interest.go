@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+)
+
+type setInterestExpenseAccountRequest struct {
+	Currency string `json:"currency" binding:"required,currency"`
+	AccountID int64 `json:"account_id" binding:"required,min=1"`
+}
+
+// sets the bank account interest of the currency is paid from
+func (server *Server) setInterestExpenseAccount(ctx *gin.Context) error {
+	var req setInterestExpenseAccountRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	if err := server.checkCurrency(req.Currency); err != nil {
+		return err
+	}
+
+	if _, err := server.validAccount(ctx, req.AccountID, req.Currency); err != nil {
+		return err
+	}
+
+	expenseAccount, err := server.store.SetInterestExpenseAccount(ctx, db.SetInterestExpenseAccountParams{
+		Currency: req.Currency,
+		AccountID: req.AccountID,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, expenseAccount)
+	return nil
+}
+
+type interestReportRequest struct {
+	FromDate time.Time `form:"from_date" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	ToDate time.Time `form:"to_date" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+}
+
+type interestReportResponse struct {
+	FromDate string `json:"from_date"`
+	ToDate string `json:"to_date"`
+	Accounts []db.GetInterestAccrualReportRow `json:"accounts"`
+	Postings []db.InterestPosting `json:"postings"`
+}
+
+// lists what every account accrued in [from_date, to_date) and the postings of periods ending in (from_date, to_date],
+// so finance can reconcile accruals against the interest entries
+func (server *Server) getInterestReport(ctx *gin.Context) error {
+	var req interestReportRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	if !req.ToDate.After(req.FromDate) {
+		apiErr := newError(http.StatusBadRequest, codeValidationFailed, "request validation failed")
+		apiErr.Details = []fieldError{{Field: "to_date", Rule: "gtfield", Message: "must be after from_date"}}
+		return apiErr
+	}
+
+	accounts, err := server.store.GetInterestAccrualReport(ctx, db.GetInterestAccrualReportParams{
+		FromDate: req.FromDate,
+		ToDate: req.ToDate,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	postings, err := server.store.ListInterestPostings(ctx, db.ListInterestPostingsParams{
+		FromDate: req.FromDate,
+		ToDate: req.ToDate,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, interestReportResponse{
+		FromDate: req.FromDate.Format("2006-01-02"),
+		ToDate: req.ToDate.Format("2006-01-02"),
+		Accounts: accounts,
+		Postings: postings,
+	})
+	return nil
+}
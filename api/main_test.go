@@ -7,17 +7,28 @@ import (
 
 	"github.com/gin-gonic/gin"
 	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/token"
 	"github.com/mateusribs/simple_bank/util"
 	"github.com/stretchr/testify/require"
 )
 
+// shared by every test server, so tests can seal secrets before the server exists
+var testTOTPEncryptionKey = util.RandomString(32)
+
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
 		TokenSymmetricKey: util.RandomString(32),
+		TOTPEncryptionKey: testTOTPEncryptionKey,
 		AccessTokenDuration: time.Minute,
+		StepUpTokenDuration: 5 * time.Minute,
+		TransferChallengeDuration: 15 * time.Minute,
+		HoldDuration: 7 * 24 * time.Hour,
 	}
 
-	server, err := NewServer(config, store)
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	server, err := NewServer(config, store, tokenMaker, db.NewCurrencyCatalog())
 	require.NoError(t, err)
 
 	return server
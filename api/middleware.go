@@ -1,13 +1,22 @@
 package api
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
+	"runtime/debug"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/logger"
+	"github.com/mateusribs/simple_bank/metrics"
 	"github.com/mateusribs/simple_bank/token"
+	"github.com/mateusribs/simple_bank/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 
@@ -15,31 +24,116 @@ const (
 	authorizationHeaderKey = "authorization"
 	authorizationTypeBearer = "bearer"
 	authorizationPayloadKey = "authorization_payload"
+	requestIDHeaderKey = "X-Request-ID"
+	requestIDKey = "request_id"
 )
 
+// longest client supplied request ID that is kept; longer ones are replaced
+const maxRequestIDLength = 128
+
+// tags the request with the X-Request-ID of the client or a new one, and echoes it in the response
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeaderKey)
+
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Set(requestIDKey, requestID)
+		ctx.Header(requestIDHeaderKey, requestID)
+		trace.SpanFromContext(ctx.Request.Context()).SetAttributes(attribute.String(logger.RequestIDKey, requestID))
+		ctx.Request = ctx.Request.WithContext(logger.WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Next()
+	}
+}
+
+// logs every request once it completes, as an error when it failed with a 5xx
+func loggerMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		log := logger.FromContext(ctx)
+		event := log.Info()
+
+		if status >= http.StatusInternalServerError {
+			event = log.Error()
+
+			if len(ctx.Errors) > 0 {
+				event = event.Err(ctx.Errors.Last().Err)
+			}
+		}
+
+		event.
+			Str("protocol", "http").
+			Str("method", ctx.Request.Method).
+			Str("path", ctx.Request.URL.Path).
+			Int("status", status).
+			Dur("duration", time.Since(start)).
+			Int("size", ctx.Writer.Size()).
+			Str("client_ip", ctx.ClientIP()).
+			Msg("request completed")
+	}
+}
+
+// observes the latency and status of every request under its route template; unmatched paths share one series
+func metricsMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequestDuration.
+			WithLabelValues(ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// turns a panic into a 500 in the error envelope, logging the stack
+func recoveryMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.FromContext(ctx).Error().
+					Interface("panic", recovered).
+					Str("stack", string(debug.Stack())).
+					Msg("handler panicked")
+				abortWithError(ctx, fmt.Errorf("panic: %v", recovered))
+			}
+		}()
+
+		ctx.Next()
+	}
+}
+
 func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 
 		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, newError(http.StatusUnauthorized, codeUnauthenticated, "authorization header is not provided"))
 			return
 		}
 
 		fields := strings.Fields(authorizationHeader)
 
 		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, newError(http.StatusUnauthorized, codeUnauthenticated, "invalid authorization header format"))
 			return
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 
 		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			message := fmt.Sprintf("unsupported authorization type %s", authorizationType)
+			abortWithError(ctx, newError(http.StatusUnauthorized, codeUnauthenticated, message))
 			return
 		}
 
@@ -48,11 +142,33 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		payload, err := tokenMaker.VerifyToken(accessToken)
 
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, newError(http.StatusUnauthorized, codeUnauthenticated, err.Error()))
 			return
 		}
 
+		logger.SetUsername(ctx, payload.Username)
 		ctx.Set(authorizationPayloadKey, payload)
 		ctx.Next()
 	}
-}
\ No newline at end of file
+}
+
+// allows only users with the admin role; must run after authMiddleware
+func adminMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		user, err := store.GetUser(ctx, authPayload.Username)
+
+		if err != nil {
+			abortWithError(ctx, orNotFound(err, newError(http.StatusForbidden, codeAdminRequired, "admin role is required")))
+			return
+		}
+
+		if user.Role != util.AdminRole {
+			abortWithError(ctx, newError(http.StatusForbidden, codeAdminRequired, "admin role is required"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -8,8 +10,21 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	mockdb "github.com/mateusribs/simple_bank/db/mock"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/logger"
+	"github.com/mateusribs/simple_bank/metrics"
 	"github.com/mateusribs/simple_bank/token"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
 )
 
 
@@ -118,4 +133,114 @@ func TestMiddleware(t *testing.T) {
 			tc.checkResponse(t, recorder)
 		})
 	}
-}
\ No newline at end of file
+}
+func addElevatedAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker token.Maker,
+	username string,
+	duration time.Duration,
+) {
+	token, payload, err := tokenMaker.CreateElevatedToken(username, duration)
+
+	require.NoError(t, err)
+	require.True(t, payload.Elevated)
+
+	authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+	request.Header.Set(authorizationHeaderKey, authorizationHeader)
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	var out bytes.Buffer
+
+	previous := log.Logger
+	log.Logger = logger.New(&out)
+	defer func() { log.Logger = previous }()
+
+	server := newTestServer(t, nil)
+
+	server.router.POST("/logged", authMiddleware(server.tokenMaker), func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{})
+	})
+
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPost, "/logged", nil)
+	require.NoError(t, err)
+
+	request.Header.Set(requestIDHeaderKey, "request-1")
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &event))
+	require.Equal(t, "request-1", event[logger.RequestIDKey])
+	require.Equal(t, "user", event[logger.UsernameKey])
+	require.Equal(t, "/logged", event["path"])
+	require.Equal(t, float64(http.StatusOK), event["status"])
+	require.NotContains(t, out.String(), request.Header.Get(authorizationHeaderKey))
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	// samples of the series, which other tests may have added to already
+	sampleCount := func(route string, status string) uint64 {
+		observer, err := metrics.HTTPRequestDuration.GetMetricWithLabelValues(http.MethodGet, route, status)
+		require.NoError(t, err)
+
+		var metric dto.Metric
+		require.NoError(t, observer.(prometheus.Histogram).Write(&metric))
+		return metric.GetHistogram().GetSampleCount()
+	}
+
+	routeBefore := sampleCount("/accounts/:id", "401")
+	unmatchedBefore := sampleCount("unmatched", "404")
+
+	for _, url := range []string{"/accounts/1", "/accounts/2", "/no/such/route"} {
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+
+		server.router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	require.Equal(t, routeBefore+2, sampleCount("/accounts/:id", "401"))
+	require.Equal(t, unmatchedBefore+1, sampleCount("unmatched", "404"))
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account := db.Account{ID: 7, Owner: "user", Currency: util.EUR}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	server := newTestServer(t, store)
+
+	body := bytes.NewBufferString(`{"from_account_id": 7, "to_account_id": 8, "amount": 10, "currency": "USD"}`)
+	request, err := http.NewRequest(http.MethodPost, "/transfers", body)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", time.Minute)
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	validAccount, handler := spans[0], spans[1]
+	require.Equal(t, "validAccount", validAccount.Name())
+	require.Contains(t, validAccount.Attributes(), attribute.Int64("account.id", account.ID))
+	require.Equal(t, "/transfers", handler.Name())
+	require.Equal(t, handler.SpanContext().SpanID(), validAccount.Parent().SpanID())
+}
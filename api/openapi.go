@@ -0,0 +1,420 @@
+package api
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// describes a route of setupRouter; the schemas come from the structs the handler binds and responds with
+type routeDoc struct {
+	summary string
+	// structs bound from the path, the query string and the JSON body
+	request []any
+	response any
+	// body of a 202 response, for routes that may defer the work
+	accepted any
+	// besides the 400 of requests with input, the 401 of authenticated routes, the 403 of admin routes and the 500 of every route
+	errors []int
+	public bool
+}
+
+var routeDocs = map[string]routeDoc{
+	"POST /users": {
+		summary: "Create a user",
+		request: []any{createUserRequest{}},
+		response: userResponse{},
+		errors: []int{http.StatusForbidden},
+		public: true,
+	},
+	"POST /users/login": {
+		summary: "Log in and open a session",
+		request: []any{loginUserRequest{}},
+		response: loginUserResponse{},
+		errors: []int{http.StatusUnauthorized, http.StatusNotFound},
+		public: true,
+	},
+	"POST /tokens/renew_access": {
+		summary: "Issue an access token from a refresh token",
+		request: []any{renewAccessTokenRequest{}},
+		response: renewAccessTokenResponse{},
+		errors: []int{http.StatusUnauthorized, http.StatusNotFound},
+		public: true,
+	},
+	"GET /currencies": {
+		summary: "List the currencies accounts can be opened in",
+		response: []currencyResponse{},
+		public: true,
+	},
+	"POST /accounts": {
+		summary: "Open an account",
+		request: []any{createAccountRequest{}},
+		response: accountResponse{},
+		errors: []int{http.StatusForbidden},
+	},
+	"GET /accounts/:id": {
+		summary: "Get an account",
+		request: []any{getAccountRequest{}},
+		response: accountResponse{},
+		errors: []int{http.StatusNotFound},
+	},
+	"GET /accounts": {
+		summary: "List the accounts of the user",
+		request: []any{listAccountRequest{}},
+		response: []accountResponse{},
+	},
+	"POST /accounts/update": {
+		summary: "Update the balance of an account",
+		request: []any{updateAccountRequest{}},
+		response: accountResponse{},
+	},
+	"POST /users/totp": {
+		summary: "Enroll a TOTP authenticator",
+		request: []any{enrollTOTPRequest{}},
+		response: enrollTOTPResponse{},
+		errors: []int{http.StatusNotFound},
+	},
+	"POST /users/totp/confirm": {
+		summary: "Confirm a TOTP enrollment with a code of the new secret",
+		request: []any{confirmTOTPRequest{}},
+		response: userResponse{},
+		errors: []int{http.StatusNotFound},
+	},
+	"POST /tokens/step_up": {
+		summary: "Re-authenticate for a short-lived elevated token",
+		request: []any{stepUpTokenRequest{}},
+		response: renewAccessTokenResponse{},
+		errors: []int{http.StatusNotFound},
+	},
+	"POST /transfers": {
+		summary: "Transfer money; large amounts answer 202 with a challenge to confirm",
+		request: []any{transferRequest{}},
+		response: db.TransferTxResult{},
+		accepted: transferChallengeResponse{},
+		errors: []int{http.StatusNotFound, http.StatusUnprocessableEntity},
+	},
+	"POST /transfers/quote": {
+		summary: "Preview the fee of a transfer",
+		request: []any{quoteTransferRequest{}},
+		response: quoteTransferResponse{},
+		errors: []int{http.StatusUnprocessableEntity},
+	},
+	"POST /transfers/challenges/:id/confirm": {
+		summary: "Run a pending transfer with an elevated token",
+		request: []any{confirmTransferChallengeRequest{}},
+		response: db.TransferTxResult{},
+		errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusGone, http.StatusUnprocessableEntity},
+	},
+	"POST /holds": {
+		summary: "Authorize a payment by holding funds",
+		request: []any{createHoldRequest{}},
+		response: db.AuthorizeHoldTxResult{},
+		errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity},
+	},
+	"GET /holds/:id": {
+		summary: "Get a hold",
+		request: []any{holdURI{}},
+		response: db.Hold{},
+		errors: []int{http.StatusNotFound},
+	},
+	"POST /holds/:id/capture": {
+		summary: "Capture a hold, in full by default",
+		request: []any{holdURI{}, captureHoldRequest{}},
+		response: db.CaptureHoldTxResult{},
+		errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusGone, http.StatusUnprocessableEntity},
+	},
+	"POST /holds/:id/void": {
+		summary: "Release a hold",
+		request: []any{holdURI{}},
+		response: db.Hold{},
+		errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusGone},
+	},
+	"POST /admin/transfer_limits": {
+		summary: "Set the transfer limits of an account or a tier",
+		request: []any{upsertTransferLimitRequest{}},
+		response: transferLimitResponse{},
+		errors: []int{http.StatusNotFound},
+	},
+	"GET /admin/transfer_limits/accounts/:id": {
+		summary: "Get the transfer limits applying to an account",
+		request: []any{getTransferLimitRequest{}},
+		response: transferLimitResponse{},
+		errors: []int{http.StatusNotFound},
+	},
+	"POST /admin/users/tier": {
+		summary: "Move a user to another tier",
+		request: []any{updateUserTierRequest{}},
+		response: userTierResponse{},
+		errors: []int{http.StatusNotFound},
+	},
+	"POST /admin/fee_schedules": {
+		summary: "Add a fee schedule tier",
+		request: []any{createFeeScheduleRequest{}},
+		response: db.FeeSchedule{},
+		errors: []int{http.StatusNotFound},
+	},
+	"GET /admin/fee_schedules": {
+		summary: "List the fee schedules",
+		response: []db.FeeSchedule{},
+	},
+	"POST /admin/interest/expense_accounts": {
+		summary: "Set the account interest is paid from for a currency",
+		request: []any{setInterestExpenseAccountRequest{}},
+		response: db.InterestExpenseAccount{},
+	},
+	"GET /admin/interest/report": {
+		summary: "Report accrued and posted interest",
+		request: []any{interestReportRequest{}},
+		response: interestReportResponse{},
+	},
+	"POST /admin/currencies": {
+		summary: "Add, enable or disable a currency",
+		request: []any{upsertCurrencyRequest{}},
+		response: db.Currency{},
+	},
+	"GET /admin/currencies": {
+		summary: "List the currency catalogue",
+		response: []db.Currency{},
+	},
+}
+
+type openAPIDocument struct {
+	OpenAPI string `json:"openapi"`
+	Info openAPIInfo `json:"info"`
+	Paths map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `json:"schemas"`
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type string `json:"type"`
+	Scheme string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type openAPIOperation struct {
+	Summary string `json:"summary,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+	Parameters []openAPIParameter `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody `json:"requestBody,omitempty"`
+	Responses map[string]openAPIResponse `json:"responses"`
+	Security []map[string][]string `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name string `json:"name"`
+	In string `json:"in"`
+	Required bool `json:"required,omitempty"`
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool `json:"required"`
+	Content map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string `json:"description"`
+	Content map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+const bearerAuth = "bearerAuth"
+
+// builds the OpenAPI 3 document of the routes in routeDocs
+func (server *Server) openAPIDocument() openAPIDocument {
+	registry := newSchemaRegistry(passwordRules(server))
+
+	doc := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{Title: "Simple Bank API", Version: "1.0"},
+		Paths: map[string]map[string]*openAPIOperation{},
+		Components: openAPIComponents{
+			Schemas: registry.schemas,
+			SecuritySchemes: map[string]openAPISecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "PASETO"},
+			},
+		},
+	}
+
+	for route, routeDoc := range routeDocs {
+		method, routePath, _ := strings.Cut(route, " ")
+		openAPIPath := openAPIPath(routePath)
+
+		if doc.Paths[openAPIPath] == nil {
+			doc.Paths[openAPIPath] = map[string]*openAPIOperation{}
+		}
+
+		doc.Paths[openAPIPath][strings.ToLower(method)] = registry.operation(routePath, routeDoc)
+	}
+
+	if body, ok := registry.schemas["errorBody"]; ok {
+		body.Properties["code"].Enum = errorCodes
+	}
+
+	return doc
+}
+
+func (registry *schemaRegistry) operation(routePath string, routeDoc routeDoc) *openAPIOperation {
+	admin := strings.HasPrefix(routePath, "/admin/")
+
+	op := &openAPIOperation{
+		Summary: routeDoc.summary,
+		Tags: []string{routeTag(routePath)},
+		Responses: map[string]openAPIResponse{},
+	}
+
+	for _, request := range routeDoc.request {
+		t := reflect.TypeOf(request)
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			if name, ok := field.Tag.Lookup("uri"); ok {
+				schema, _ := registry.fieldSchema(t, field)
+				op.Parameters = append(op.Parameters, openAPIParameter{Name: name, In: "path", Required: true, Schema: schema})
+			} else if name, ok := field.Tag.Lookup("form"); ok {
+				schema, required := registry.fieldSchema(t, field)
+				op.Parameters = append(op.Parameters, openAPIParameter{Name: name, In: "query", Required: required, Schema: schema})
+			} else if _, ok := field.Tag.Lookup("json"); ok && op.RequestBody == nil {
+				op.RequestBody = &openAPIRequestBody{
+					Required: true,
+					Content: jsonContent(registry.schemaFor(t)),
+				}
+			}
+		}
+	}
+
+	op.Responses[strconv.Itoa(http.StatusOK)] = registry.response(http.StatusOK, routeDoc.response)
+
+	if routeDoc.accepted != nil {
+		op.Responses[strconv.Itoa(http.StatusAccepted)] = registry.response(http.StatusAccepted, routeDoc.accepted)
+	}
+
+	errors := append([]int{http.StatusInternalServerError}, routeDoc.errors...)
+
+	if len(routeDoc.request) > 0 {
+		errors = append(errors, http.StatusBadRequest)
+	}
+
+	if !routeDoc.public {
+		errors = append(errors, http.StatusUnauthorized)
+		op.Security = []map[string][]string{{bearerAuth: {}}}
+	}
+
+	if admin {
+		errors = append(errors, http.StatusForbidden)
+	}
+
+	for _, code := range errors {
+		op.Responses[strconv.Itoa(code)] = registry.response(code, errorResponse{})
+	}
+
+	return op
+}
+
+func (registry *schemaRegistry) response(code int, body any) openAPIResponse {
+	return openAPIResponse{
+		Description: http.StatusText(code),
+		Content: jsonContent(registry.schemaFor(reflect.TypeOf(body))),
+	}
+}
+
+func jsonContent(schema *openAPISchema) map[string]openAPIMediaType {
+	return map[string]openAPIMediaType{"application/json": {Schema: schema}}
+}
+
+// converts gin parameters such as :id to OpenAPI templates such as {id}
+func openAPIPath(routePath string) string {
+	segments := strings.Split(routePath, "/")
+
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// groups operations by their first path segment, admin routes by their second
+func routeTag(routePath string) string {
+	segments := strings.Split(strings.TrimPrefix(routePath, "/"), "/")
+
+	if segments[0] == "admin" && len(segments) > 1 {
+		return "admin"
+	}
+
+	return segments[0]
+}
+
+// the rules of the configured password policy, for the description of password fields
+func passwordRules(server *Server) string {
+	policy := server.passwordPolicy
+	rules := []string{fmt.Sprintf("at least %d characters", policy.MinLength)}
+
+	if policy.RequireUpper {
+		rules = append(rules, "an uppercase letter")
+	}
+
+	if policy.RequireLower {
+		rules = append(rules, "a lowercase letter")
+	}
+
+	if policy.RequireDigit {
+		rules = append(rules, "a digit")
+	}
+
+	if policy.RequireSymbol {
+		rules = append(rules, "a symbol")
+	}
+
+	rules = append(rules, "must not contain the username or email (of 3 characters or more)")
+
+	if policy.Breached != nil {
+		rules = append(rules, "must not be a known breached password")
+	}
+
+	return strings.Join(rules, "; ")
+}
+
+//go:embed swagger/index.html
+var swaggerIndex []byte
+
+// serves the OpenAPI document built at startup
+func (server *Server) getOpenAPI(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/json", server.openAPI)
+}
+
+// serves Swagger UI pointed at /openapi.json
+func swaggerHandler() gin.HandlerFunc {
+	fileServer := http.StripPrefix("/swagger", http.FileServer(http.FS(swaggerFiles.FS)))
+
+	return func(ctx *gin.Context) {
+		switch ctx.Param("filepath") {
+		case "/", "/index.html":
+			ctx.Data(http.StatusOK, "text/html; charset=utf-8", swaggerIndex)
+		default:
+			fileServer.ServeHTTP(ctx.Writer, ctx.Request)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// the subset of the OpenAPI 3 schema object the API needs
+type openAPISchema struct {
+	Ref string `json:"$ref,omitempty"`
+	Type string `json:"type,omitempty"`
+	Format string `json:"format,omitempty"`
+	Description string `json:"description,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	Enum []string `json:"enum,omitempty"`
+	Minimum *int64 `json:"minimum,omitempty"`
+	Maximum *int64 `json:"maximum,omitempty"`
+	ExclusiveMinimum bool `json:"exclusiveMinimum,omitempty"`
+	MinLength *int64 `json:"minLength,omitempty"`
+	MaxLength *int64 `json:"maxLength,omitempty"`
+	Nullable bool `json:"nullable,omitempty"`
+	Items *openAPISchema `json:"items,omitempty"`
+	Properties map[string]*openAPISchema `json:"properties,omitempty"`
+	Required []string `json:"required,omitempty"`
+	AdditionalProperties *openAPISchema `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+)
+
+// builds schemas from Go types, registering named structs as components
+type schemaRegistry struct {
+	schemas map[string]*openAPISchema
+	names map[reflect.Type]string
+	// describes fields tagged policy:"password"
+	passwordRules string
+}
+
+func newSchemaRegistry(passwordRules string) *schemaRegistry {
+	return &schemaRegistry{
+		schemas: map[string]*openAPISchema{},
+		names: map[reflect.Type]string{},
+		passwordRules: passwordRules,
+	}
+}
+
+// returns the schema of a type, a reference for named structs
+func (registry *schemaRegistry) schemaFor(t reflect.Type) *openAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &openAPISchema{Type: "string", Format: "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: registry.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: registry.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return registry.structSchema(t)
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + registry.component(t)}
+	}
+
+	return &openAPISchema{}
+}
+
+// registers the struct under its type name, qualified by the package when two packages share a name
+func (registry *schemaRegistry) component(t reflect.Type) string {
+	if name, ok := registry.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+
+	if _, taken := registry.schemas[name]; taken {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+
+	registry.names[t] = name
+	// reserve the name before recursing, so self-referencing types terminate
+	registry.schemas[name] = &openAPISchema{}
+	*registry.schemas[name] = *registry.structSchema(t)
+
+	return name
+}
+
+func (registry *schemaRegistry) structSchema(t reflect.Type) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	registry.addFields(schema, t)
+	return schema
+}
+
+// adds the JSON fields of the struct, flattening embedded structs as encoding/json does
+func (registry *schemaRegistry) addFields(schema *openAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag, hasTag := field.Tag.Lookup("json")
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && !hasTag && field.Type.Kind() == reflect.Struct {
+			registry.addFields(schema, field.Type)
+			continue
+		}
+
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property, required := registry.fieldSchema(t, field)
+		schema.Properties[name] = property
+
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// the schema of a field with the constraints of its binding tag
+func (registry *schemaRegistry) fieldSchema(parent reflect.Type, field reflect.StructField) (*openAPISchema, bool) {
+	schema := registry.schemaFor(field.Type)
+
+	if schema.Ref != "" {
+		return schema, hasRule(field, "required")
+	}
+
+	if field.Type == timeType {
+		if layout := field.Tag.Get("time_format"); layout == "2006-01-02" {
+			schema.Format = "date"
+		}
+	}
+
+	required := false
+	numeric := schema.Type == "integer" || schema.Type == "number"
+
+	for _, rule := range bindingRules(field) {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "required":
+			required = true
+		case "required_without":
+			schema.Description = fmt.Sprintf("required unless %s is set", jsonName(parent, param))
+		case "min", "max", "gt", "len":
+			value, err := strconv.ParseInt(param, 10, 64)
+
+			if err != nil {
+				continue
+			}
+
+			switch {
+			case name == "len":
+				schema.MinLength, schema.MaxLength = &value, &value
+			case name == "max" && numeric:
+				schema.Maximum = &value
+			case name == "max":
+				schema.MaxLength = &value
+			case numeric:
+				schema.Minimum = &value
+				schema.ExclusiveMinimum = name == "gt"
+			default:
+				schema.MinLength = &value
+			}
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "email":
+			schema.Format = "email"
+		case "uuid":
+			schema.Format = "uuid"
+		case "alphanum":
+			schema.Pattern = "^[a-zA-Z0-9]+$"
+		case "alpha":
+			schema.Pattern = "^[a-zA-Z]+$"
+		case "numeric":
+			schema.Pattern = "^[0-9]+$"
+		case "currency":
+			schema.Pattern = "^[A-Z]{3}$"
+			schema.Description = "ISO 4217 code of a currency enabled in the catalogue, see GET /currencies"
+		}
+	}
+
+	// checked by the handler against the password policy of the server
+	if field.Tag.Get("policy") == "password" {
+		schema.Format = "password"
+		schema.Description = registry.passwordRules
+	}
+
+	return schema, required
+}
+
+func bindingRules(field reflect.StructField) []string {
+	binding := field.Tag.Get("binding")
+
+	if binding == "" {
+		return nil
+	}
+
+	return strings.Split(binding, ",")
+}
+
+func hasRule(field reflect.StructField, rule string) bool {
+	for _, r := range bindingRules(field) {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// the name a field referenced by required_without has on the wire
+func jsonName(parent reflect.Type, goName string) string {
+	field, ok := parent.FieldByName(goName)
+
+	if !ok {
+		return goName
+	}
+
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+
+	return goName
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mockdb "github.com/mateusribs/simple_bank/db/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// routes serving the documentation itself and the probes of the orchestrator
+var undocumentedRoutes = map[string]bool{
+	"GET /openapi.json": true,
+	"GET /swagger/*filepath": true,
+	"GET /healthz": true,
+	"GET /readyz": true,
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+	registered := map[string]bool{}
+
+	for _, route := range server.router.Routes() {
+		key := route.Method + " " + route.Path
+
+		if undocumentedRoutes[key] {
+			continue
+		}
+
+		registered[key] = true
+		require.Contains(t, routeDocs, key, "route is missing from routeDocs in openapi.go")
+	}
+
+	for key := range routeDocs {
+		require.True(t, registered[key], "routeDocs documents %s, which setupRouter does not register", key)
+	}
+}
+
+func TestOpenAPISecurityMatchesRouter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+	for key, doc := range routeDocs {
+		method, path, _ := strings.Cut(key, " ")
+		path = strings.ReplaceAll(path, ":id", "1")
+
+		// without a token the auth middleware answers 401 before the handler binds anything
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(method, path, strings.NewReader("{}"))
+		require.NoError(t, err)
+
+		server.router.ServeHTTP(recorder, request)
+
+		if doc.public {
+			require.NotEqual(t, http.StatusUnauthorized, recorder.Code, key)
+		} else {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code, key)
+		}
+	}
+}
+
+func TestGetOpenAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var doc openAPIDocument
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &doc))
+	require.Equal(t, "3.0.3", doc.OpenAPI)
+	require.Contains(t, doc.Components.SecuritySchemes, bearerAuth)
+
+	// binding constraints
+	createUser := doc.Components.Schemas["createUserRequest"]
+	require.NotNil(t, createUser)
+	require.ElementsMatch(t, []string{"username", "password", "full_name", "email"}, createUser.Required)
+	require.Equal(t, "email", createUser.Properties["email"].Format)
+	require.Equal(t, "^[a-zA-Z0-9]+$", createUser.Properties["username"].Pattern)
+	require.Contains(t, createUser.Properties["password"].Description, "at least 6 characters")
+
+	transfer := doc.Components.Schemas["transferRequest"]
+	require.Equal(t, int64(0), *transfer.Properties["amount"].Minimum)
+	require.True(t, transfer.Properties["amount"].ExclusiveMinimum)
+	require.Equal(t, int64(1), *transfer.Properties["from_account_id"].Minimum)
+	require.Equal(t, "^[A-Z]{3}$", transfer.Properties["currency"].Pattern)
+
+	stepUp := doc.Components.Schemas["stepUpTokenRequest"]
+	require.Equal(t, "required unless totp_code is set", stepUp.Properties["password"].Description)
+	require.Equal(t, int64(6), *stepUp.Properties["totp_code"].MaxLength)
+
+	// embedded structs are flattened
+	account := doc.Components.Schemas["accountResponse"]
+	require.Contains(t, account.Properties, "balance")
+	require.Contains(t, account.Properties, "balance_display")
+
+	// query and path parameters
+	listAccounts := doc.Paths["/accounts"]["get"]
+	require.Len(t, listAccounts.Parameters, 2)
+	require.Equal(t, "query", listAccounts.Parameters[1].In)
+	require.Equal(t, int64(5), *listAccounts.Parameters[1].Schema.Minimum)
+	require.Equal(t, int64(10), *listAccounts.Parameters[1].Schema.Maximum)
+
+	getAccount := doc.Paths["/accounts/{id}"]["get"]
+	require.Equal(t, "path", getAccount.Parameters[0].In)
+	require.True(t, getAccount.Parameters[0].Required)
+	require.Contains(t, getAccount.Responses, "404")
+
+	report := doc.Paths["/admin/interest/report"]["get"]
+	require.Equal(t, "date", report.Parameters[0].Schema.Format)
+	require.Contains(t, report.Responses, "403")
+
+	// errors and security
+	createTransfer := doc.Paths["/transfers"]["post"]
+	require.NotEmpty(t, createTransfer.Security)
+	require.Contains(t, createTransfer.Responses, "202")
+	require.Equal(t, "#/components/schemas/errorResponse", createTransfer.Responses["422"].Content["application/json"].Schema.Ref)
+	require.Equal(t, "#/components/schemas/errorResponse", createTransfer.Responses["401"].Content["application/json"].Schema.Ref)
+
+	errorBody := doc.Components.Schemas["errorBody"]
+	require.ElementsMatch(t, []string{"code", "message", "request_id"}, errorBody.Required)
+	require.Contains(t, errorBody.Properties["code"].Enum, codeInsufficientFunds)
+	require.Contains(t, errorBody.Properties, "limit")
+
+	createUserOp := doc.Paths["/users"]["post"]
+	require.Empty(t, createUserOp.Security)
+	require.NotContains(t, createUserOp.Responses, "401")
+}
+
+func TestSwaggerUI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/swagger/index.html", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), `url: "/openapi.json"`)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/swagger/swagger-ui-bundle.js", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
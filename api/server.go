@@ -1,14 +1,19 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
-	"github.com/go-playground/validator/v10"
 	db "github.com/mateusribs/simple_bank/db/sqlc"
 	"github.com/mateusribs/simple_bank/token"
+	"github.com/mateusribs/simple_bank/tracing"
 	"github.com/mateusribs/simple_bank/util"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // serves HTTP requests for banking services
@@ -16,53 +21,129 @@ type Server struct {
 	config util.Config
 	store db.Store
 	tokenMaker token.Maker
+	passwordHasher util.PasswordHasher
+	passwordPolicy *util.PasswordPolicy
+	currencies *db.CurrencyCatalog
 	router *gin.Engine
+	openAPI []byte
+	httpServer *http.Server
+	// set once shutdown begins, failing the readiness probe
+	draining atomic.Bool
 }
 
 
-// create a new server instance
-func NewServer(config util.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+// create a new server instance; the token maker and the currency catalogue are shared with the gRPC server
+func NewServer(config util.Config, store db.Store, tokenMaker token.Maker, currencies *db.CurrencyCatalog) (*Server, error) {
+	passwordHasher, err := util.NewPasswordHasher(config)
 
 	if err != nil {
-		return nil, fmt.Errorf("cannot create token maker: %w", err)
+		return nil, fmt.Errorf("cannot create password hasher: %w", err)
 	}
-	server := &Server{config: config, store: store, tokenMaker: tokenMaker}
-	
 
-	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("currency", validCurrency)
+	passwordPolicy, err := util.NewPasswordPolicy(config)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password policy: %w", err)
+	}
+
+	server := &Server{
+		config: config,
+		store: store,
+		tokenMaker: tokenMaker,
+		passwordHasher: passwordHasher,
+		passwordPolicy: passwordPolicy,
+		currencies: currencies,
 	}
 
 	server.setupRouter()
 
+	server.httpServer = &http.Server{
+		Handler: server.router,
+		ReadHeaderTimeout: config.HTTPReadHeaderTimeout,
+		ReadTimeout: config.HTTPReadTimeout,
+		WriteTimeout: config.HTTPWriteTimeout,
+		IdleTimeout: config.HTTPIdleTimeout,
+	}
+
+	server.openAPI, err = json.Marshal(server.openAPIDocument())
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot build OpenAPI document: %w", err)
+	}
+
 	return server, nil
 }
 
 func (server *Server) setupRouter(){
-	router := gin.Default()
-
-	router.POST("/users", server.createUser)
-	router.POST("/users/login", server.loginUser)
-	router.POST("/tokens/renew_access", server.renewAccessToken)
+	router := gin.New()
+	// lets store calls made with the gin context see the request logger
+	router.ContextWithFallback = true
+	router.Use(otelgin.Middleware(tracing.ServiceName), requestIDMiddleware(), loggerMiddleware(), metricsMiddleware(), errorMiddleware(), recoveryMiddleware())
+	router.NoRoute(func(ctx *gin.Context) {
+		ctx.Error(newError(http.StatusNotFound, codeRouteNotFound, "route not found"))
+	})
+
+	router.POST("/users", handle(server.createUser))
+	router.POST("/users/login", handle(server.loginUser))
+	router.POST("/tokens/renew_access", handle(server.renewAccessToken))
+	router.GET("/healthz", server.healthz)
+	router.GET("/readyz", server.readyz)
+	router.GET("/currencies", server.listCurrencies)
+	router.GET("/openapi.json", server.getOpenAPI)
+	router.GET("/swagger/*filepath", swaggerHandler())
 
 	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
 
-	authRoutes.POST("/accounts", server.createAccount)
-	authRoutes.GET("/accounts/:id", server.getAccount)
-	authRoutes.GET("/accounts", server.listAccount)
-	authRoutes.POST("/accounts/update", server.updateAccount)
+	authRoutes.POST("/accounts", handle(server.createAccount))
+	authRoutes.GET("/accounts/:id", handle(server.getAccount))
+	authRoutes.GET("/accounts", handle(server.listAccount))
+	authRoutes.POST("/accounts/update", handle(server.updateAccount))
 
-	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.POST("/users/totp", handle(server.enrollTOTP))
+	authRoutes.POST("/users/totp/confirm", handle(server.confirmTOTP))
+	authRoutes.POST("/tokens/step_up", handle(server.stepUpToken))
+
+	authRoutes.POST("/transfers", handle(server.createTransfer))
+	authRoutes.POST("/transfers/quote", handle(server.quoteTransfer))
+	authRoutes.POST("/transfers/challenges/:id/confirm", handle(server.confirmTransferChallenge))
+
+	authRoutes.POST("/holds", handle(server.createHold))
+	authRoutes.GET("/holds/:id", handle(server.getHold))
+	authRoutes.POST("/holds/:id/capture", handle(server.captureHold))
+	authRoutes.POST("/holds/:id/void", handle(server.voidHold))
+
+	adminRoutes := router.Group("/admin").Use(authMiddleware(server.tokenMaker), adminMiddleware(server.store))
+
+	adminRoutes.POST("/transfer_limits", handle(server.upsertTransferLimit))
+	adminRoutes.GET("/transfer_limits/accounts/:id", handle(server.getTransferLimit))
+	adminRoutes.POST("/users/tier", handle(server.updateUserTier))
+	adminRoutes.POST("/fee_schedules", handle(server.createFeeSchedule))
+	adminRoutes.GET("/fee_schedules", handle(server.listFeeSchedules))
+	adminRoutes.POST("/interest/expense_accounts", handle(server.setInterestExpenseAccount))
+	adminRoutes.GET("/interest/report", handle(server.getInterestReport))
+	adminRoutes.POST("/currencies", handle(server.upsertCurrency))
+	adminRoutes.GET("/currencies", handle(server.listAllCurrencies))
 
 	server.router = router
 }
 
-// runs HTTP server on a specific address
+// runs HTTP server on a specific address until Shutdown is called, which makes it return nil
 func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+	server.httpServer.Addr = address
+
+	err := server.httpServer.ListenAndServe()
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
-}
\ No newline at end of file
+// stops accepting connections and waits for the running requests to finish, or for ctx to expire.
+// Call StartDraining first so the orchestrator stops sending requests.
+func (server *Server) Shutdown(ctx context.Context) error {
+	server.StartDraining()
+
+	return server.httpServer.Shutdown(ctx)
+}
@@ -1,12 +1,12 @@
 package api
 
 import (
-	"database/sql"
-	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mateusribs/simple_bank/auth"
+	"github.com/mateusribs/simple_bank/token"
 )
 
 
@@ -19,62 +19,45 @@ type renewAccessTokenResponse struct {
 	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
 }
 
-func (server *Server) renewAccessToken(ctx *gin.Context){
+func (server *Server) renewAccessToken(ctx *gin.Context) error {
 	var req renewAccessTokenRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return invalidRequest(err)
 	}
 
 	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
 
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+		return newError(http.StatusUnauthorized, codeUnauthenticated, err.Error())
 	}
 
 	session, err := server.store.GetSession(ctx, refreshPayload.ID)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return orNotFound(err, errSessionNotFound)
 	}
 
 	if session.IsBlocked {
-		err := fmt.Errorf("blocked session")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+		return newError(http.StatusUnauthorized, codeInvalidSession, "blocked session")
 	}
 
 	if session.Username != refreshPayload.Username {
-		err := fmt.Errorf("incorrect session user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+		return newError(http.StatusUnauthorized, codeInvalidSession, "incorrect session user")
 	}
 
 	if session.RefreshToken != req.RefreshToken {
-		err := fmt.Errorf("mismatched session token")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+		return newError(http.StatusUnauthorized, codeInvalidSession, "mismatched session token")
 	}
 
 	if time.Now().After(session.ExpiresAt) {
-		err := fmt.Errorf("expired session")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+		return newError(http.StatusUnauthorized, codeInvalidSession, "expired session")
 	}
 
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(refreshPayload.Username, server.config.AccessTokenDuration)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return err
 	}
 
 	rsp := renewAccessTokenResponse{
@@ -83,4 +66,49 @@ func (server *Server) renewAccessToken(ctx *gin.Context){
 	}
 
 	ctx.JSON(http.StatusOK, rsp)
-}
\ No newline at end of file
+	return nil
+}
+
+type stepUpTokenRequest struct {
+	Password string `json:"password" binding:"required_without=TOTPCode"`
+	TOTPCode string `json:"totp_code" binding:"required_without=Password,omitempty,len=6,numeric"`
+}
+
+// re-authenticates the user with the password or a TOTP code and issues a short-lived elevated access token
+func (server *Server) stepUpToken(ctx *gin.Context) error {
+	var req stepUpTokenRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+
+	if err != nil {
+		return orNotFound(err, errUserNotFound)
+	}
+
+	if req.TOTPCode != "" {
+		if err := auth.CheckTOTP(ctx, server.store, server.config.TOTPEncryptionKey, user, req.TOTPCode); err != nil {
+			return err
+		}
+	} else if err := server.passwordHasher.CheckPassword(req.Password, user.HashedPassword); err != nil {
+		return errInvalidCredentials
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateElevatedToken(user.Username, server.config.StepUpTokenDuration)
+
+	if err != nil {
+		return err
+	}
+
+	rsp := renewAccessTokenResponse{
+		AccessToken: accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+	return nil
+}
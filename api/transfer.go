@@ -1,14 +1,17 @@
 package api
 
 import (
-	"database/sql"
-	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	db "github.com/mateusribs/simple_bank/db/sqlc"
 	"github.com/mateusribs/simple_bank/token"
+	"github.com/mateusribs/simple_bank/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type transferRequest struct {
@@ -18,53 +21,55 @@ type transferRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
 }
 
-func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
-	account, err := server.store.GetAccount(ctx, accountID)
+func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, error) {
+	spanCtx, span := tracing.Tracer().Start(ctx, "validAccount", trace.WithAttributes(attribute.Int64("account.id", accountID)))
+	defer span.End()
+
+	// from the primary, since the transfer may use an account created a moment ago
+	account, err := server.store.GetAccount(db.WithReadYourWrites(spanCtx), accountID)
 
 	if err != nil {
-		if err == sql.ErrNoRows{
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return account, false
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return account, false
+		return account, orNotFound(err, errAccountNotFound)
 	}
 
 	if account.Currency != currency {
-		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return account, false
+		message := fmt.Sprintf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
+		return account, newError(http.StatusBadRequest, codeCurrencyMismatch, message)
 	}
 
-	return account, true
+	return account, nil
 }
 
-func (server *Server) createTransfer(ctx *gin.Context){
+func (server *Server) createTransfer(ctx *gin.Context) error {
 	var req transferRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return invalidRequest(err)
+	}
+
+	if err := server.checkCurrency(req.Currency); err != nil {
+		return err
 	}
 
-	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	fromAccount, err := server.validAccount(ctx, req.FromAccountID, req.Currency)
 
-	if !valid{
-		return
+	if err != nil {
+		return err
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
 	if fromAccount.Owner != authPayload.Username {
-		err := errors.New("from account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+		return newError(http.StatusUnauthorized, codeNotOwner, "from account does not belong to the authenticated user")
 	}
 
-	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if _, err := server.validAccount(ctx, req.ToAccountID, req.Currency); err != nil {
+		return err
+	}
 
-	if !valid{
-		return
+	// a stolen access token must not be enough to move large amounts
+	if server.requiresStepUp(req.Amount) && !authPayload.Elevated {
+		return server.createTransferChallenge(ctx, authPayload.Username, req)
 	}
 
 	arg := db.TransferTxParams{
@@ -76,9 +81,94 @@ func (server *Server) createTransfer(ctx *gin.Context){
 	result, err := server.store.TransferTx(ctx, arg)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, result)
+	return nil
+}
+
+func (server *Server) requiresStepUp(amount int64) bool {
+	return server.config.StepUpTransferAmount > 0 && amount >= server.config.StepUpTransferAmount
+}
+
+type transferChallengeResponse struct {
+	ChallengeID uuid.UUID `json:"challenge_id"`
+	Status string `json:"status"`
+	Amount int64 `json:"amount"`
+	Methods []string `json:"methods"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// stores the transfer as pending and tells the client how to confirm it
+func (server *Server) createTransferChallenge(ctx *gin.Context, username string, req transferRequest) error {
+	challengeID, err := uuid.NewRandom()
+
+	if err != nil {
+		return err
+	}
+
+	challenge, err := server.store.CreateTransferChallenge(ctx, db.CreateTransferChallengeParams{
+		ID: challengeID,
+		Username: username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID: req.ToAccountID,
+		Amount: req.Amount,
+		ExpiresAt: time.Now().Add(server.config.TransferChallengeDuration),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	rsp := transferChallengeResponse{
+		ChallengeID: challenge.ID,
+		Status: challenge.Status,
+		Amount: challenge.Amount,
+		Methods: []string{"password", "totp"},
+		ExpiresAt: challenge.ExpiresAt,
+	}
+
+	ctx.JSON(http.StatusAccepted, rsp)
+	return nil
+}
+
+type confirmTransferChallengeRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// runs a pending transfer once the user presents an elevated token
+func (server *Server) confirmTransferChallenge(ctx *gin.Context) error {
+	var req confirmTransferChallengeRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if !authPayload.Elevated {
+		return newError(http.StatusForbidden, codeElevationRequired, "an elevated token is required, re-authenticate at /tokens/step_up")
+	}
+
+	challengeID := uuid.MustParse(req.ID)
+
+	challenge, err := server.store.GetTransferChallenge(ctx, challengeID)
+
+	if err != nil {
+		return orNotFound(err, errChallengeNotFound)
+	}
+
+	if challenge.Username != authPayload.Username {
+		return newError(http.StatusUnauthorized, codeNotOwner, "transfer challenge does not belong to the authenticated user")
+	}
+
+	result, err := server.store.CompleteTransferChallengeTx(ctx, challengeID)
+
+	if err != nil {
+		return err
 	}
 
 	ctx.JSON(http.StatusOK, result)
-}
\ No newline at end of file
+	return nil
+}
@@ -2,45 +2,57 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	mockdb "github.com/mateusribs/simple_bank/db/mock"
+	"github.com/google/uuid"
 	db "github.com/mateusribs/simple_bank/db/sqlc"
 	"github.com/mateusribs/simple_bank/token"
 	"github.com/mateusribs/simple_bank/util"
 	"github.com/stretchr/testify/require"
-	"go.uber.org/mock/gomock"
 )
 
 
 func TestCreateTransfer(t *testing.T){
-	
+
 	amount := int64(100)
 	invalidID := int64(5430)
 
-	user1, _ := randomUser(t)
-	user2, _ := randomUser(t)
-	user3, _ := randomUser(t)
+	store := db.NewMemStore()
+
+	user1 := createTestUser(t, store)
+	user2 := createTestUser(t, store)
+	user3 := createTestUser(t, store)
+	user4 := createTestUser(t, store)
+
+	account1 := createTestAccount(t, store, user1.Username, util.BRL, 10 * amount)
+	account2 := createTestAccount(t, store, user2.Username, util.BRL, 0)
+	account3 := createTestAccount(t, store, user3.Username, util.EUR, 0)
 
-	account1 := randomAccount(user1.Username)
-	account2 := randomAccount(user2.Username)
-	account3 := randomAccount(user3.Username)
+	// already sent 50 of its daily limit of 120
+	limited := createTestAccount(t, store, user4.Username, util.BRL, 10 * amount)
 
-	account1.Currency = "BRL"
-	account2.Currency = "BRL"
-	account3.Currency = "EUR"
+	_, err := store.UpsertAccountTransferLimit(context.Background(), db.UpsertAccountTransferLimitParams{
+		AccountID: sql.NullInt64{Int64: limited.ID, Valid: true},
+		MaxDailyAmount: 120,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{FromAccountID: limited.ID, ToAccountID: account2.ID, Amount: 50})
+	require.NoError(t, err)
 
 	testCases := []struct{
 		name string
 		body gin.H
+		failing bool
 		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
-		buildStubs func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
@@ -49,27 +61,25 @@ func TestCreateTransfer(t *testing.T){
 				"from_account_id": account1.ID,
 				"to_account_id": account2.ID,
 				"amount": amount,
-				"currency": util.BRL, 
+				"currency": util.BRL,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore){
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
-
-				arg := db.TransferTxParams{
-					FromAccountID: account1.ID,
-					ToAccountID: account2.ID,
-					Amount: amount,
-				}
-
-				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
 				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.TransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.Equal(t, account1.ID, result.Transfer.FromAccountID)
+				require.Equal(t, account2.ID, result.Transfer.ToAccountID)
+				require.Equal(t, amount, result.Transfer.Amount)
+
+				from, err := store.GetAccount(context.Background(), account1.ID)
+				require.NoError(t, err)
+				require.Equal(t, account1.Balance - amount, from.Balance)
 			},
 		},
 		{
@@ -78,19 +88,13 @@ func TestCreateTransfer(t *testing.T){
 				"from_account_id": account1.ID,
 				"to_account_id": account2.ID,
 				"amount": amount,
-				"currency": util.BRL, 
+				"currency": util.BRL,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore){
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
-
-				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0	)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -101,15 +105,10 @@ func TestCreateTransfer(t *testing.T){
 				"from_account_id": account1.ID,
 				"to_account_id": account2.ID,
 				"amount": amount,
-				"currency": util.BRL, 
+				"currency": util.BRL,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
-			buildStubs: func(store *mockdb.MockStore){
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
-
-				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -120,16 +119,13 @@ func TestCreateTransfer(t *testing.T){
 				"from_account_id": 0,
 				"to_account_id": account2.ID,
 				"amount": amount,
-				"currency": util.BRL, 
+				"currency": util.BRL,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore){
-				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
@@ -140,16 +136,13 @@ func TestCreateTransfer(t *testing.T){
 				"from_account_id": account1.ID,
 				"to_account_id": account2.ID,
 				"amount": amount,
-				"currency": "MEX", 
+				"currency": "MEX",
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore){
-				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
@@ -160,16 +153,13 @@ func TestCreateTransfer(t *testing.T){
 				"from_account_id": account1.ID,
 				"to_account_id": account2.ID,
 				"amount": -amount,
-				"currency": util.BRL, 
+				"currency": util.BRL,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore){
-				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
@@ -180,16 +170,13 @@ func TestCreateTransfer(t *testing.T){
 				"from_account_id": invalidID,
 				"to_account_id": account2.ID,
 				"amount": amount,
-				"currency": util.BRL, 
+				"currency": util.BRL,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore){
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(invalidID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
@@ -200,16 +187,14 @@ func TestCreateTransfer(t *testing.T){
 				"from_account_id": account1.ID,
 				"to_account_id": account2.ID,
 				"amount": amount,
-				"currency": util.BRL, 
+				"currency": util.BRL,
 			},
+			failing: true,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore){
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
@@ -220,37 +205,54 @@ func TestCreateTransfer(t *testing.T){
 				"from_account_id": account1.ID,
 				"to_account_id": account3.ID,
 				"amount": amount,
-				"currency": util.BRL, 
+				"currency": util.BRL,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(
 					t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute,
 				)
 			},
-			buildStubs: func(store *mockdb.MockStore){
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
-			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "TransferLimitExceeded",
+			body: gin.H{
+				"from_account_id": limited.ID,
+				"to_account_id": account2.ID,
+				"amount": amount,
+				"currency": util.BRL,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(
+					t, request, tokenMaker, authorizationTypeBearer, user4.Username, time.Minute,
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var body errorResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, codeTransferLimitExceeded, body.Error.Code)
+				require.Equal(t, db.LimitDailyAmount, body.Error.Limit.Limit)
+				require.Equal(t, int64(50), body.Error.Limit.Used)
+			},
+		},
 	}
 
 	for i := range testCases {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			store := mockdb.NewMockStore(ctrl)
+			var testStore db.Store = store
 
-			//build stubs
-			tc.buildStubs(store)
+			if tc.failing {
+				testStore = failingAccountStore{store}
+			}
 
 			//start test server and send request
-			server := newTestServer(t, store)
+			server := newTestServer(t, testStore)
 			recorder := httptest.NewRecorder()
 
 			data, err := json.Marshal(tc.body)
@@ -269,4 +271,240 @@ func TestCreateTransfer(t *testing.T){
 			tc.checkResponse(t, recorder)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestCreateTransferStepUp(t *testing.T){
+	amount := int64(1000)
+
+	store := db.NewMemStore()
+
+	user1 := createTestUser(t, store)
+	user2 := createTestUser(t, store)
+
+	account1 := createTestAccount(t, store, user1.Username, util.BRL, 10 * amount)
+	account2 := createTestAccount(t, store, user2.Username, util.BRL, 0)
+
+	// the balance of account2 tells whether a transfer ran
+	requireReceived := func(t *testing.T, received int64) {
+		account, err := store.GetAccount(context.Background(), account2.ID)
+		require.NoError(t, err)
+		require.Equal(t, received, account.Balance)
+	}
+
+	testCases := []struct{
+		name string
+		amount int64
+		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "BelowThreshold",
+			amount: amount - 1,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireReceived(t, amount - 1)
+			},
+		},
+		{
+			name: "ChallengeRequired",
+			amount: amount,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var rsp transferChallengeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotZero(t, rsp.ChallengeID)
+				require.Equal(t, db.ChallengePending, rsp.Status)
+				require.Equal(t, amount, rsp.Amount)
+
+				challenge, err := store.GetTransferChallenge(context.Background(), rsp.ChallengeID)
+				require.NoError(t, err)
+				require.Equal(t, user1.Username, challenge.Username)
+				require.Equal(t, account1.ID, challenge.FromAccountID)
+				require.Equal(t, account2.ID, challenge.ToAccountID)
+				require.Equal(t, amount, challenge.Amount)
+
+				// nothing moves until the challenge is confirmed
+				requireReceived(t, amount - 1)
+			},
+		},
+		{
+			name: "ElevatedToken",
+			amount: amount,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addElevatedAuthorization(t, request, tokenMaker, user1.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireReceived(t, 2 * amount - 1)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, store)
+			server.config.StepUpTransferAmount = amount
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id": account2.ID,
+				"amount": tc.amount,
+				"currency": util.BRL,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestConfirmTransferChallenge(t *testing.T){
+	store := db.NewMemStore()
+
+	user := createTestUser(t, store)
+	other := createTestUser(t, store)
+
+	fromAccount := createTestAccount(t, store, user.Username, util.BRL, 100000)
+	toAccount := createTestAccount(t, store, other.Username, util.BRL, 0)
+
+	// a pending transfer of user that expires at expiresAt
+	createChallenge := func(t *testing.T, expiresAt time.Time) uuid.UUID {
+		challenge, err := store.CreateTransferChallenge(context.Background(), db.CreateTransferChallengeParams{
+			ID: uuid.New(),
+			Username: user.Username,
+			FromAccountID: fromAccount.ID,
+			ToAccountID: toAccount.ID,
+			Amount: util.RandomInt(1, 1000),
+			ExpiresAt: expiresAt,
+		})
+		require.NoError(t, err)
+
+		return challenge.ID
+	}
+
+	testCases := []struct{
+		name string
+		challengeID func(t *testing.T) uuid.UUID
+		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			challengeID: func(t *testing.T) uuid.UUID {
+				return createChallenge(t, time.Now().Add(time.Minute))
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addElevatedAuthorization(t, request, tokenMaker, user.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.TransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.Equal(t, fromAccount.ID, result.Transfer.FromAccountID)
+				require.Equal(t, toAccount.ID, result.Transfer.ToAccountID)
+			},
+		},
+		{
+			name: "NotElevated",
+			challengeID: func(t *testing.T) uuid.UUID {
+				return createChallenge(t, time.Now().Add(time.Minute))
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "OtherUser",
+			challengeID: func(t *testing.T) uuid.UUID {
+				return createChallenge(t, time.Now().Add(time.Minute))
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addElevatedAuthorization(t, request, tokenMaker, other.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			challengeID: func(t *testing.T) uuid.UUID {
+				return uuid.New()
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addElevatedAuthorization(t, request, tokenMaker, user.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyCompleted",
+			challengeID: func(t *testing.T) uuid.UUID {
+				id := createChallenge(t, time.Now().Add(time.Minute))
+
+				_, err := store.CompleteTransferChallengeTx(context.Background(), id)
+				require.NoError(t, err)
+
+				return id
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addElevatedAuthorization(t, request, tokenMaker, user.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Expired",
+			challengeID: func(t *testing.T) uuid.UUID {
+				return createChallenge(t, time.Now().Add(-time.Minute))
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addElevatedAuthorization(t, request, tokenMaker, user.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
+				require.Equal(t, http.StatusGone, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			challengeID := tc.challengeID(t)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/challenges/%s/confirm", challengeID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
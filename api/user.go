@@ -2,19 +2,24 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/lib/pq"
+	"github.com/mateusribs/simple_bank/auth"
 	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/metrics"
+	"github.com/mateusribs/simple_bank/token"
 	"github.com/mateusribs/simple_bank/util"
 )
 
+const totpIssuer = "SimpleBank"
+
 type createUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required" policy:"password"`
 	FullName string `json:"full_name" binding:"required"`
 	Email string `json:"email" binding:"required,email"`
 }
@@ -51,19 +56,23 @@ func newUserResponse(user db.User) userResponse {
 	}
 }
 
-func (server *Server) createUser(ctx *gin.Context) {
+var errInvalidCredentials = newError(http.StatusUnauthorized, codeInvalidCredentials, "incorrect password")
+
+func (server *Server) createUser(ctx *gin.Context) error {
 	var req createUserRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return invalidRequest(err)
+	}
+
+	if err := server.passwordPolicy.Validate(req.Password, req.Username, req.Email); err != nil {
+		return err
 	}
 
-	HashedPassword, err := util.HashPassword(req.Password)
+	HashedPassword, err := server.passwordHasher.HashPassword(req.Password)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return err
 	}
 
 	arg := db.CreateUserParams{
@@ -76,55 +85,49 @@ func (server *Server) createUser(ctx *gin.Context) {
 	user, err := server.store.CreateUser(ctx, arg)
 
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return err
 	}
 
 	resp := newUserResponse(user)
 
 	ctx.JSON(http.StatusOK, resp)
-
+	return nil
 }
 
-func (server *Server) loginUser(ctx *gin.Context) {
+func (server *Server) loginUser(ctx *gin.Context) error {
 	var req loginUserRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return invalidRequest(err)
 	}
 
 	user, err := server.store.GetUser(ctx, req.Username)
 
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
+	if errors.Is(err, sql.ErrNoRows) {
+		metrics.LoginFailures.WithLabelValues("http", metrics.LoginUnknownUser).Inc()
+		return errUserNotFound
+	}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+	if err != nil {
+		return err
 	}
 
-	err = util.CheckPassword(req.Password, user.HashedPassword)
+	err = server.passwordHasher.CheckPassword(req.Password, user.HashedPassword)
 
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+		metrics.LoginFailures.WithLabelValues("http", metrics.LoginWrongPassword).Inc()
+		return errInvalidCredentials
+	}
+
+	// upgrade hashes made with an outdated algorithm or parameters while the plain password is at hand
+	if server.passwordHasher.NeedsRehash(user.HashedPassword) {
+		auth.RehashPassword(ctx, server.store, server.passwordHasher, user.Username, req.Password)
 	}
 
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return err
 	}
 
 	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
@@ -133,8 +136,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 	)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return err
 	}
 	
 	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
@@ -148,8 +150,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 	})
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return err
 	}
 
 	rsp := loginUserResponse{
@@ -162,4 +163,119 @@ func (server *Server) loginUser(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusOK, rsp)
+	return nil
+}
+
+type enrollTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type enrollTOTPResponse struct {
+	TOTPSecret string `json:"totp_secret"`
+	TOTPURL string `json:"totp_url"`
+}
+
+// generates a new TOTP secret for the user and keeps it pending until confirmTOTP receives a code of it,
+// so the current secret stays in use if the new one never reaches an authenticator; the password is
+// required so a stolen access token alone cannot enroll an attacker's authenticator
+func (server *Server) enrollTOTP(ctx *gin.Context) error {
+	var req enrollTOTPRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+
+	if err != nil {
+		return orNotFound(err, errUserNotFound)
+	}
+
+	if err := server.passwordHasher.CheckPassword(req.Password, user.HashedPassword); err != nil {
+		return errInvalidCredentials
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+
+	if err != nil {
+		return err
+	}
+
+	encryptedSecret, err := util.EncryptSecret(server.config.TOTPEncryptionKey, secret)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = server.store.UpdateUserTOTPPendingSecret(ctx, db.UpdateUserTOTPPendingSecretParams{
+		Username: user.Username,
+		TotpPendingSecret: encryptedSecret,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	rsp := enrollTOTPResponse{
+		TOTPSecret: secret,
+		TOTPURL: util.TOTPURL(totpIssuer, user.Username, secret),
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+	return nil
+}
+
+type confirmTOTPRequest struct {
+	TOTPCode string `json:"totp_code" binding:"required,len=6,numeric"`
+}
+
+var errInvalidTOTPCode = newError(http.StatusUnauthorized, codeInvalidCredentials, "invalid TOTP code")
+
+// replaces the TOTP secret of the user with the pending one once a code of it shows the authenticator holds it
+func (server *Server) confirmTOTP(ctx *gin.Context) error {
+	var req confirmTOTPRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return invalidRequest(err)
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+
+	if err != nil {
+		return orNotFound(err, errUserNotFound)
+	}
+
+	if user.TotpPendingSecret == "" {
+		return newError(http.StatusBadRequest, codeTOTPNotEnrolled, "no TOTP enrollment is pending for this user")
+	}
+
+	secret, err := util.DecryptSecret(server.config.TOTPEncryptionKey, user.TotpPendingSecret)
+
+	if err != nil {
+		return err
+	}
+
+	counter, ok := util.ValidateTOTP(secret, req.TOTPCode, time.Now(), 0)
+
+	if !ok {
+		return errInvalidTOTPCode
+	}
+
+	// no rows when another enrollment replaced the pending secret after it was read
+	user, err = server.store.ConfirmUserTOTPSecret(ctx, db.ConfirmUserTOTPSecretParams{
+		Username: user.Username,
+		TotpPendingSecret: user.TotpPendingSecret,
+		TotpLastCounter: counter,
+	})
+
+	if err != nil {
+		return orNotFound(err, errInvalidTOTPCode)
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+	return nil
 }
@@ -9,12 +9,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	mockdb "github.com/mateusribs/simple_bank/db/mock"
 	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/metrics"
 	"github.com/mateusribs/simple_bank/util"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -138,6 +142,22 @@ func TestCreateUserAPI(t *testing.T){
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "PasswordContainsUsername",
+			body: gin.H{
+				"username": user.Username,
+				"password": "my" + user.Username + "pass",
+				"full_name": user.FullName,
+				"email": user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "must not contain the username")
+			},
+		},
 		{
 			name: "InvalidEmail",
 			body: gin.H{
@@ -204,6 +224,16 @@ func TestCreateUserAPI(t *testing.T){
 func TestLoginUserAPI(t *testing.T) {
 	user, password := randomUser(t)
 
+	bcryptHasher, err := util.NewPasswordHasher(util.Config{PasswordHashAlgorithm: util.Bcrypt})
+	require.NoError(t, err)
+
+	legacyUser := user
+	legacyUser.HashedPassword, err = bcryptHasher.HashPassword(password)
+	require.NoError(t, err)
+
+	wrongPasswordFailures := metrics.LoginFailures.WithLabelValues("http", metrics.LoginWrongPassword)
+	wrongPasswordsBefore := testutil.ToFloat64(wrongPasswordFailures)
+
 	testCases := []struct{
 		name string
 		body gin.H
@@ -224,6 +254,60 @@ func TestLoginUserAPI(t *testing.T) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "RehashOutdatedPassword",
+			body: gin.H{
+				"username": legacyUser.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(legacyUser.Username)).Times(1).Return(legacyUser, nil)
+				store.EXPECT().
+					UpdateUserHashedPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpdateUserHashedPasswordParams) (db.User, error) {
+						require.Equal(t, legacyUser.Username, arg.Username)
+						require.True(t, strings.HasPrefix(arg.HashedPassword, "$argon2id$"))
+						require.NoError(t, util.CheckPassword(password, arg.HashedPassword))
+						return legacyUser, nil
+					})
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RehashFailureDoesNotBlockLogin",
+			body: gin.H{
+				"username": legacyUser.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(legacyUser.Username)).Times(1).Return(legacyUser, nil)
+				store.EXPECT().UpdateUserHashedPassword(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrConnDone)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{
+				"username": user.Username,
+				"password": "wrongpassword",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().UpdateUserHashedPassword(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, wrongPasswordsBefore+1, testutil.ToFloat64(wrongPasswordFailures))
+			},
+		},
 		{
 			name: "ErrorInJSONRequest",
 			body: gin.H{
@@ -309,4 +393,154 @@ func TestLoginUserAPI(t *testing.T) {
 			tc.checkResponse(recorder)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestEnrollTOTPAPI(t *testing.T) {
+	user, password := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var pendingSecret string
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	// the current secret stays until the new one is confirmed
+	store.EXPECT().UpdateUserTOTPSecret(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().
+		UpdateUserTOTPPendingSecret(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg db.UpdateUserTOTPPendingSecretParams) (db.User, error) {
+			pendingSecret = arg.TotpPendingSecret
+			return user, nil
+		})
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"password": password})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/users/totp", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp enrollTOTPResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+
+	// stored encrypted, never as the secret handed to the user
+	require.True(t, util.IsEncryptedSecret(pendingSecret))
+
+	decrypted, err := util.DecryptSecret(testTOTPEncryptionKey, pendingSecret)
+	require.NoError(t, err)
+	require.Equal(t, rsp.TOTPSecret, decrypted)
+}
+
+func TestConfirmTOTPAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	secret, err := util.GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	pendingUser := user
+	pendingUser.TotpPendingSecret, err = util.EncryptSecret(testTOTPEncryptionKey, secret)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	code, err := util.GenerateTOTPCode(secret, now)
+	require.NoError(t, err)
+
+	otherSecret, err := util.GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	wrongCode, err := util.GenerateTOTPCode(otherSecret, now)
+	require.NoError(t, err)
+
+	testCases := []struct{
+		name string
+		code string
+		buildStubs func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			code: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ConfirmUserTOTPSecretParams{
+					Username: user.Username,
+					TotpPendingSecret: pendingUser.TotpPendingSecret,
+					TotpLastCounter: now.Unix() / 30,
+				}
+
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(pendingUser, nil)
+				store.EXPECT().ConfirmUserTOTPSecret(gomock.Any(), gomock.Eq(arg)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongCode",
+			code: wrongCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(pendingUser, nil)
+				store.EXPECT().ConfirmUserTOTPSecret(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireErrorResponse(t, recorder, http.StatusUnauthorized, codeInvalidCredentials)
+			},
+		},
+		{
+			name: "NothingPending",
+			code: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().ConfirmUserTOTPSecret(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireErrorResponse(t, recorder, http.StatusBadRequest, codeTOTPNotEnrolled)
+			},
+		},
+		{
+			name: "ReplacedByAnotherEnrollment",
+			code: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(pendingUser, nil)
+				store.EXPECT().ConfirmUserTOTPSecret(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireErrorResponse(t, recorder, http.StatusUnauthorized, codeInvalidCredentials)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"totp_code": tc.code})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/totp/confirm", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
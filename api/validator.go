@@ -1,14 +1,62 @@
 package api
 
 import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
-	"github.com/mateusribs/simple_bank/util"
+	"github.com/mateusribs/simple_bank/money"
 )
 
+// registers the custom tags on the validator gin binds every request with; they hold for every
+// server, so what depends on one, such as its currency catalogue or password policy, is checked
+// by its handlers instead
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterValidation("currency", validCurrency)
+		v.RegisterTagNameFunc(wireName)
+	}
+}
 
+// accepts ISO 4217 codes; checkCurrency then asks the catalogue of the server whether the code is enabled
 var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
-	if currency, ok := fieldLevel.Field().Interface().(string); ok {
-		return util.IsSupportedCurrency(currency)
+	currency, ok := fieldLevel.Field().Interface().(string)
+
+	if !ok {
+		return false
 	}
-	return false
-}
\ No newline at end of file
+
+	_, ok = money.Lookup(currency)
+	return ok
+}
+
+// rejects a currency the catalogue of the server does not enable, in the shape of a failed binding
+func (server *Server) checkCurrency(currency string) error {
+	if server.currencies.IsEnabled(currency) {
+		return nil
+	}
+
+	apiErr := newError(http.StatusBadRequest, codeValidationFailed, "request validation failed")
+	apiErr.Details = []fieldError{{Field: "currency", Rule: "currency", Message: "must be an enabled currency, see GET /currencies"}}
+
+	return apiErr
+}
+
+// the name of a field on the wire, so validation details name json, query and uri fields as clients send them
+func wireName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+
+		if name == "-" {
+			return ""
+		}
+
+		if name != "" {
+			return name
+		}
+	}
+
+	return field.Name
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/logger"
+	"github.com/mateusribs/simple_bank/util"
+)
+
+// stores a fresh hash of the password, for hashes made with an outdated algorithm or parameters;
+// a failure is only logged, so it never fails the login that has the plain password at hand
+func RehashPassword(ctx context.Context, store db.Querier, hasher util.PasswordHasher, username string, password string) {
+	hashedPassword, err := hasher.HashPassword(password)
+
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("username", username).Msg("cannot rehash password")
+		return
+	}
+
+	_, err = store.UpdateUserHashedPassword(ctx, db.UpdateUserHashedPasswordParams{
+		Username: username,
+		HashedPassword: hashedPassword,
+	})
+
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("username", username).Msg("cannot store rehashed password")
+	}
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/util"
+)
+
+var (
+	ErrTOTPNotEnrolled = errors.New("TOTP is not enrolled for this user")
+	ErrInvalidTOTPCode = errors.New("invalid TOTP code")
+)
+
+// checks a TOTP code of the user against the secret sealed with key and claims its time step,
+// so the same code cannot be used again
+func CheckTOTP(ctx context.Context, store db.Querier, key string, user db.User, code string) error {
+	if user.TotpSecret == "" {
+		return ErrTOTPNotEnrolled
+	}
+
+	secret, err := util.DecryptSecret(key, user.TotpSecret)
+
+	if err != nil {
+		return err
+	}
+
+	counter, ok := util.ValidateTOTP(secret, code, time.Now(), user.TotpLastCounter)
+
+	if !ok {
+		return ErrInvalidTOTPCode
+	}
+
+	// no rows when a concurrent request used a code of this step first
+	_, err = store.UseUserTOTPCounter(ctx, db.UseUserTOTPCounterParams{
+		Username: user.Username,
+		TotpLastCounter: counter,
+	})
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidTOTPCode
+	}
+
+	return err
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTOTP(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemStore()
+	key := util.RandomString(util.EncryptionKeySize)
+
+	user, err := store.CreateUser(ctx, db.CreateUserParams{
+		Username: util.RandomOwner(),
+		HashedPassword: util.RandomString(32),
+		FullName: util.RandomOwner(),
+		Email: util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, CheckTOTP(ctx, store, key, user, "123456"), ErrTOTPNotEnrolled)
+
+	secret, err := util.GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	// stored as it is, the secret was never sealed with the key
+	user.TotpSecret = secret
+	require.ErrorIs(t, CheckTOTP(ctx, store, key, user, "123456"), util.ErrInvalidEncryptedSecret)
+
+	user.TotpSecret, err = util.EncryptSecret(key, secret)
+	require.NoError(t, err)
+
+	code, err := util.GenerateTOTPCode(secret, time.Now())
+	require.NoError(t, err)
+
+	require.NoError(t, CheckTOTP(ctx, store, key, user, code))
+
+	// a request that read the user before the code was used still cannot use it again
+	require.ErrorIs(t, CheckTOTP(ctx, store, key, user, code), ErrInvalidTOTPCode)
+}
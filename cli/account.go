@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+)
+
+// accounts fetched per query while listing
+const accountPageSize = 100
+
+var accountHeader = []string{"ID", "OWNER", "CURRENCY", "BALANCE", "AVAILABLE", "PRODUCT", "FROZEN"}
+
+func accountRow(account db.Account) []string {
+	return []string{
+		strconv.FormatInt(account.ID, 10),
+		account.Owner,
+		account.Currency,
+		strconv.FormatInt(account.Balance, 10),
+		strconv.FormatInt(account.AvailableBalance, 10),
+		account.Product,
+		strconv.FormatBool(account.IsFrozen),
+	}
+}
+
+func (cli *CLI) listAccounts(inv *invocation) error {
+	owner := inv.flags.String("owner", "", "username owning the accounts")
+
+	if err := inv.parse(); err != nil {
+		return err
+	}
+
+	if *owner == "" {
+		return inv.required("owner")
+	}
+
+	accounts := []db.Account{}
+
+	for offset := int32(0); ; offset += accountPageSize {
+		page, err := cli.store.ListAccounts(inv.ctx, db.ListAccountsParams{
+			Owner: *owner,
+			Limit: accountPageSize,
+			Offset: offset,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		accounts = append(accounts, page...)
+
+		if len(page) < accountPageSize {
+			break
+		}
+	}
+
+	rows := make([][]string, 0, len(accounts))
+
+	for _, account := range accounts {
+		rows = append(rows, accountRow(account))
+	}
+
+	return cli.print(inv, accounts, accountHeader, rows)
+}
+
+func (cli *CLI) freezeAccount(inv *invocation) error {
+	id := inv.flags.Int64("id", 0, "account to freeze")
+	unfreeze := inv.flags.Bool("unfreeze", false, "lift the freeze instead")
+
+	if err := inv.parse(); err != nil {
+		return err
+	}
+
+	if *id <= 0 {
+		return inv.required("id")
+	}
+
+	account, err := cli.store.GetAccount(inv.ctx, *id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("account %d not found", *id)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	action := fmt.Sprintf("freeze account %d", account.ID)
+
+	if *unfreeze {
+		action = fmt.Sprintf("unfreeze account %d", account.ID)
+	}
+
+	if inv.dryRun {
+		account.IsFrozen = !*unfreeze
+	} else {
+		account, err = cli.store.SetAccountFrozen(inv.ctx, db.SetAccountFrozenParams{
+			ID: account.ID,
+			IsFrozen: !*unfreeze,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return cli.printResult(inv, action, account, accountHeader, accountRow(account))
+}
@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/util"
+)
+
+// runs the support tasks operators used to do with ad-hoc SQL
+type CLI struct {
+	store db.Store
+	passwordHasher util.PasswordHasher
+	passwordPolicy *util.PasswordPolicy
+	stdin io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// create a CLI hashing and checking passwords with the same settings as the servers
+func New(config util.Config, store db.Store, stdin io.Reader, stdout io.Writer, stderr io.Writer) (*CLI, error) {
+	passwordHasher, err := util.NewPasswordHasher(config)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher: %w", err)
+	}
+
+	passwordPolicy, err := util.NewPasswordPolicy(config)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password policy: %w", err)
+	}
+
+	return &CLI{
+		store: store,
+		passwordHasher: passwordHasher,
+		passwordPolicy: passwordPolicy,
+		stdin: stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}, nil
+}
+
+type command struct {
+	group string
+	name string
+	summary string
+	// mutating commands accept --dry-run, which checks the change can be made without making it
+	mutating bool
+	run func(cli *CLI, inv *invocation) error
+}
+
+var commands = []command{
+	{"user", "create", "create a user", true, (*CLI).createUser},
+	{"user", "reset-password", "set a new password for a user and block their sessions", true, (*CLI).resetPassword},
+	{"user", "set-role", "make a user an admin or a customer", true, (*CLI).setRole},
+	{"account", "list", "list the accounts of a user", false, (*CLI).listAccounts},
+	{"account", "freeze", "stop an account from sending or receiving money", true, (*CLI).freezeAccount},
+	{"session", "block", "block a session so its refresh token stops working", true, (*CLI).blockSession},
+	{"reconcile", "", "report accounts whose balances disagree with their entries and holds", false, (*CLI).reconcile},
+	{"ledger", "export", "write the entries of a period as CSV or JSON lines", false, (*CLI).exportLedger},
+}
+
+// reported when a command was used wrongly; the usage has already been printed
+var ErrUsage = errors.New("invalid usage")
+
+// the flags and output options of one run of a command
+type invocation struct {
+	ctx context.Context
+	flags *flag.FlagSet
+	args []string
+	json bool
+	dryRun bool
+}
+
+// parses the flags the command registered, after the common ones
+func (inv *invocation) parse() error {
+	if err := inv.flags.Parse(inv.args); err != nil {
+		return ErrUsage
+	}
+
+	if inv.flags.NArg() > 0 {
+		fmt.Fprintf(inv.flags.Output(), "unexpected arguments: %s\n", strings.Join(inv.flags.Args(), " "))
+		inv.flags.Usage()
+		return ErrUsage
+	}
+
+	return nil
+}
+
+// reports a missing required flag
+func (inv *invocation) required(name string) error {
+	fmt.Fprintf(inv.flags.Output(), "--%s is required\n", name)
+	inv.flags.Usage()
+	return ErrUsage
+}
+
+// runs the command named by the first arguments, such as "user create --username alice"
+func (cli *CLI) Run(ctx context.Context, args []string) error {
+	for _, cmd := range commands {
+		rest, ok := matchCommand(cmd, args)
+
+		if !ok {
+			continue
+		}
+
+		name := strings.TrimSpace(cmd.group + " " + cmd.name)
+		inv := &invocation{
+			ctx: ctx,
+			flags: flag.NewFlagSet(name, flag.ContinueOnError),
+			args: rest,
+		}
+
+		inv.flags.SetOutput(cli.stderr)
+		inv.flags.BoolVar(&inv.json, "json", false, "print JSON instead of text")
+
+		if cmd.mutating {
+			inv.flags.BoolVar(&inv.dryRun, "dry-run", false, "check the change without making it")
+		}
+
+		return cmd.run(cli, inv)
+	}
+
+	cli.usage()
+
+	return ErrUsage
+}
+
+func matchCommand(cmd command, args []string) ([]string, bool) {
+	if len(args) == 0 || args[0] != cmd.group {
+		return nil, false
+	}
+
+	if cmd.name == "" {
+		return args[1:], true
+	}
+
+	if len(args) < 2 || args[1] != cmd.name {
+		return nil, false
+	}
+
+	return args[2:], true
+}
+
+func (cli *CLI) usage() {
+	fmt.Fprintln(cli.stderr, "usage: simplebank <command> [flags]")
+	fmt.Fprintln(cli.stderr)
+
+	w := tabwriter.NewWriter(cli.stderr, 0, 4, 2, ' ', 0)
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", strings.TrimSpace(cmd.group+" "+cmd.name), cmd.summary)
+	}
+
+	fmt.Fprintf(w, "  migrate\t%s\n", "apply or roll back the database migrations")
+	w.Flush()
+}
+
+// prints value as JSON with --json, otherwise as the rows of a table
+func (cli *CLI) print(inv *invocation, value any, header []string, rows [][]string) error {
+	if inv.json {
+		return json.NewEncoder(cli.stdout).Encode(value)
+	}
+
+	w := tabwriter.NewWriter(cli.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	return w.Flush()
+}
+
+// the outcome of a mutating command, with the record it changed or would change
+type result struct {
+	DryRun bool `json:"dry_run"`
+	Action string `json:"action"`
+	Record any `json:"record"`
+}
+
+func (cli *CLI) printResult(inv *invocation, action string, record any, header []string, row []string) error {
+	if inv.json {
+		return json.NewEncoder(cli.stdout).Encode(result{DryRun: inv.dryRun, Action: action, Record: record})
+	}
+
+	if inv.dryRun {
+		fmt.Fprintf(cli.stdout, "dry run, would %s\n", action)
+	} else {
+		fmt.Fprintf(cli.stdout, "%s: done\n", action)
+	}
+
+	return cli.print(inv, record, header, [][]string{row})
+}
+
+// the password of --password, or the first line of standard input so it stays out of the shell history
+func (cli *CLI) readPassword(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+
+	line, err := bufio.NewReader(cli.stdin).ReadString('\n')
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("cannot read password: %w", err)
+	}
+
+	password := strings.TrimRight(line, "\r\n")
+
+	if password == "" {
+		return "", errors.New("no password given with --password or on standard input")
+	}
+
+	return password, nil
+}
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/mateusribs/simple_bank/db/mock"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testPassword = "Secret123"
+
+func newTestCLI(t *testing.T, store db.Store, stdin string) (*CLI, *bytes.Buffer) {
+	var stdout, stderr bytes.Buffer
+
+	cli, err := New(util.Config{PasswordMinLength: 8}, store, strings.NewReader(stdin), &stdout, &stderr)
+	require.NoError(t, err)
+
+	return cli, &stdout
+}
+
+func TestCreateUser(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+		stdin string
+		buildStubs func(store *mockdb.MockStore)
+		check func(t *testing.T, stdout string, err error)
+	}{
+		{
+			name: "PasswordFromStdin",
+			args: []string{"user", "create", "--username", "alice", "--full-name", "Alice", "--email", "alice@example.com", "--json"},
+			stdin: testPassword + "\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), "alice").Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+						require.NoError(t, util.CheckPassword(testPassword, arg.HashedPassword))
+						return db.User{Username: arg.Username, FullName: arg.FullName, Email: arg.Email, HashedPassword: arg.HashedPassword}, nil
+					})
+			},
+			check: func(t *testing.T, stdout string, err error) {
+				require.NoError(t, err)
+				require.NotContains(t, stdout, "hashed_password")
+
+				var rsp result
+				require.NoError(t, json.Unmarshal([]byte(stdout), &rsp))
+				require.False(t, rsp.DryRun)
+				require.Equal(t, "create user alice", rsp.Action)
+			},
+		},
+		{
+			name: "DryRun",
+			args: []string{"user", "create", "--username", "alice", "--full-name", "Alice", "--email", "alice@example.com", "--password", testPassword, "--dry-run", "--json"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), "alice").Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, stdout string, err error) {
+				require.NoError(t, err)
+
+				var rsp result
+				require.NoError(t, json.Unmarshal([]byte(stdout), &rsp))
+				require.True(t, rsp.DryRun)
+			},
+		},
+		{
+			name: "AlreadyExists",
+			args: []string{"user", "create", "--username", "alice", "--full-name", "Alice", "--email", "alice@example.com", "--password", testPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), "alice").Times(1).Return(db.User{Username: "alice"}, nil)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, stdout string, err error) {
+				require.ErrorContains(t, err, "already exists")
+			},
+		},
+		{
+			name: "WeakPassword",
+			args: []string{"user", "create", "--username", "alice", "--full-name", "Alice", "--email", "alice@example.com", "--password", "short"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, stdout string, err error) {
+				var policyErr *util.PasswordPolicyError
+				require.ErrorAs(t, err, &policyErr)
+			},
+		},
+		{
+			name: "MissingFlag",
+			args: []string{"user", "create", "--username", "alice"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, stdout string, err error) {
+				require.ErrorIs(t, err, ErrUsage)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cli, stdout := newTestCLI(t, store, tc.stdin)
+			err := cli.Run(context.Background(), tc.args)
+			tc.check(t, stdout.String(), err)
+		})
+	}
+}
+
+func TestSetRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	user := db.User{Username: "alice", Role: util.CustomerRole}
+
+	store.EXPECT().GetUser(gomock.Any(), "alice").Times(1).Return(user, nil)
+	store.EXPECT().UpdateUserRole(gomock.Any(), db.UpdateUserRoleParams{Username: "alice", Role: util.AdminRole}).Times(1).
+		Return(db.User{Username: "alice", Role: util.AdminRole}, nil)
+
+	cli, stdout := newTestCLI(t, store, "")
+	require.NoError(t, cli.Run(context.Background(), []string{"user", "set-role", "--username", "alice", "--role", util.AdminRole}))
+	require.Contains(t, stdout.String(), "set the role of alice to admin: done")
+
+	err := cli.Run(context.Background(), []string{"user", "set-role", "--username", "alice", "--role", "owner"})
+	require.ErrorContains(t, err, "unknown role owner")
+}
+
+func TestFreezeAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	account := db.Account{ID: 7, Owner: "alice", Currency: util.USD}
+
+	store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+	store.EXPECT().SetAccountFrozen(gomock.Any(), db.SetAccountFrozenParams{ID: account.ID, IsFrozen: true}).Times(1).
+		Return(db.Account{ID: 7, Owner: "alice", Currency: util.USD, IsFrozen: true}, nil)
+
+	cli, stdout := newTestCLI(t, store, "")
+	require.NoError(t, cli.Run(context.Background(), []string{"account", "freeze", "--id", "7"}))
+	require.Contains(t, stdout.String(), "freeze account 7: done")
+	require.Contains(t, stdout.String(), "true")
+}
+
+func TestReconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().ReconcileAccounts(gomock.Any()).Times(1).Return([]db.ReconcileAccountsRow{
+		{ID: 1, Owner: "alice", Currency: util.USD, Balance: 100, LedgerBalance: 90, AvailableBalance: 100, ExpectedAvailableBalance: 100},
+	}, nil)
+
+	cli, stdout := newTestCLI(t, store, "")
+	err := cli.Run(context.Background(), []string{"reconcile", "--json"})
+	require.ErrorIs(t, err, ErrUnreconciled)
+
+	var rows []db.ReconcileAccountsRow
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &rows))
+	require.Len(t, rows, 1)
+	require.EqualValues(t, 90, rows[0].LedgerBalance)
+}
+
+func TestExportLedger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	firstPage := make([]db.ListLedgerEntriesRow, ledgerPageSize)
+
+	for i := range firstPage {
+		firstPage[i] = db.ListLedgerEntriesRow{ID: int64(i + 1), AccountID: 1, Currency: util.USD, Amount: -10, Type: db.EntryTypeTransfer, CreatedAt: from}
+	}
+
+	gomock.InOrder(
+		store.EXPECT().ListLedgerEntries(gomock.Any(), db.ListLedgerEntriesParams{FromTime: from, ToTime: to, AfterID: 0, PageSize: ledgerPageSize}).
+			Times(1).Return(firstPage, nil),
+		store.EXPECT().ListLedgerEntries(gomock.Any(), db.ListLedgerEntriesParams{FromTime: from, ToTime: to, AfterID: ledgerPageSize, PageSize: ledgerPageSize}).
+			Times(1).Return([]db.ListLedgerEntriesRow{{ID: ledgerPageSize + 1, AccountID: 2, Currency: util.USD, Amount: 10, Type: db.EntryTypeTransfer, CreatedAt: from}}, nil),
+	)
+
+	cli, stdout := newTestCLI(t, store, "")
+	require.NoError(t, cli.Run(context.Background(), []string{"ledger", "export", "--from", "2024-01-01", "--to", "2024-02-01"}))
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, ledgerPageSize+2)
+	require.Equal(t, "id,account_id,currency,amount,type,created_at", lines[0])
+	require.Equal(t, "1001,2,USD,10,transfer,2024-01-01T00:00:00Z", lines[len(lines)-1])
+}
+
+func TestUnknownCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	cli, _ := newTestCLI(t, store, "")
+	require.ErrorIs(t, cli.Run(context.Background(), []string{"account", "delete"}), ErrUsage)
+}
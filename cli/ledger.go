@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+)
+
+// reported by reconcile when some account is out of balance, so scripts can alert on the exit status
+var ErrUnreconciled = errors.New("accounts out of balance")
+
+func (cli *CLI) reconcile(inv *invocation) error {
+	if err := inv.parse(); err != nil {
+		return err
+	}
+
+	mismatches, err := cli.store.ReconcileAccounts(inv.ctx)
+
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(mismatches))
+
+	for _, mismatch := range mismatches {
+		rows = append(rows, []string{
+			strconv.FormatInt(mismatch.ID, 10),
+			mismatch.Owner,
+			mismatch.Currency,
+			strconv.FormatInt(mismatch.Balance, 10),
+			strconv.FormatInt(mismatch.LedgerBalance, 10),
+			strconv.FormatInt(mismatch.AvailableBalance, 10),
+			strconv.FormatInt(mismatch.ExpectedAvailableBalance, 10),
+		})
+	}
+
+	header := []string{"ID", "OWNER", "CURRENCY", "BALANCE", "SUM OF ENTRIES", "AVAILABLE", "BALANCE MINUS HOLDS"}
+
+	if err := cli.print(inv, mismatches, header, rows); err != nil {
+		return err
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%w: %d", ErrUnreconciled, len(mismatches))
+	}
+
+	return nil
+}
+
+// entries fetched per query while exporting
+const ledgerPageSize = 1000
+
+const dateLayout = "2006-01-02"
+
+// writes the entries created from --from up to, not including, --to; with --json one object per line
+func (cli *CLI) exportLedger(inv *invocation) error {
+	from := inv.flags.String("from", "", "first day to export, as YYYY-MM-DD in UTC")
+	to := inv.flags.String("to", "", "day after the last one to export, as YYYY-MM-DD in UTC")
+
+	if err := inv.parse(); err != nil {
+		return err
+	}
+
+	if *from == "" {
+		return inv.required("from")
+	}
+
+	if *to == "" {
+		return inv.required("to")
+	}
+
+	fromTime, err := time.Parse(dateLayout, *from)
+
+	if err != nil {
+		return fmt.Errorf("invalid --from: %w", err)
+	}
+
+	toTime, err := time.Parse(dateLayout, *to)
+
+	if err != nil {
+		return fmt.Errorf("invalid --to: %w", err)
+	}
+
+	if !toTime.After(fromTime) {
+		return errors.New("--to must be after --from")
+	}
+
+	encoder := json.NewEncoder(cli.stdout)
+	writer := csv.NewWriter(cli.stdout)
+
+	if !inv.json {
+		writer.Write([]string{"id", "account_id", "currency", "amount", "type", "created_at"})
+	}
+
+	var afterID int64
+
+	for {
+		entries, err := cli.store.ListLedgerEntries(inv.ctx, db.ListLedgerEntriesParams{
+			FromTime: fromTime,
+			ToTime: toTime,
+			AfterID: afterID,
+			PageSize: ledgerPageSize,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if inv.json {
+				err = encoder.Encode(entry)
+			} else {
+				err = writer.Write([]string{
+					strconv.FormatInt(entry.ID, 10),
+					strconv.FormatInt(entry.AccountID, 10),
+					entry.Currency,
+					strconv.FormatInt(entry.Amount, 10),
+					entry.Type,
+					entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+				})
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < ledgerPageSize {
+			break
+		}
+
+		afterID = entries[len(entries)-1].ID
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var sessionHeader = []string{"ID", "USERNAME", "CLIENT IP", "USER AGENT", "BLOCKED", "EXPIRES AT"}
+
+// the session without its refresh token
+type sessionView struct {
+	ID uuid.UUID `json:"id"`
+	Username string `json:"username"`
+	UserAgent string `json:"user_agent"`
+	ClientIP string `json:"client_ip"`
+	IsBlocked bool `json:"is_blocked"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (cli *CLI) blockSession(inv *invocation) error {
+	rawID := inv.flags.String("id", "", "session to block")
+
+	if err := inv.parse(); err != nil {
+		return err
+	}
+
+	if *rawID == "" {
+		return inv.required("id")
+	}
+
+	id, err := uuid.Parse(*rawID)
+
+	if err != nil {
+		return fmt.Errorf("invalid session id: %w", err)
+	}
+
+	session, err := cli.store.GetSession(inv.ctx, id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("session %s not found", id)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if inv.dryRun {
+		session.IsBlocked = true
+	} else {
+		session, err = cli.store.BlockSession(inv.ctx, id)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	view := sessionView{
+		ID: session.ID,
+		Username: session.Username,
+		UserAgent: session.UserAgent,
+		ClientIP: session.ClientIp,
+		IsBlocked: session.IsBlocked,
+		ExpiresAt: session.ExpiresAt,
+	}
+
+	row := []string{view.ID.String(), view.Username, view.ClientIP, view.UserAgent, strconv.FormatBool(view.IsBlocked), view.ExpiresAt.UTC().Format(time.RFC3339)}
+
+	return cli.printResult(inv, fmt.Sprintf("block session %s", id), view, sessionHeader, row)
+}
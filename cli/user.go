@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/util"
+)
+
+// a user without its password hash and TOTP secret
+type userView struct {
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+	Email string `json:"email"`
+	Role string `json:"role"`
+	Tier string `json:"tier"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newUserView(user db.User) userView {
+	return userView{
+		Username: user.Username,
+		FullName: user.FullName,
+		Email: user.Email,
+		Role: user.Role,
+		Tier: user.Tier,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+var userHeader = []string{"USERNAME", "FULL NAME", "EMAIL", "ROLE", "TIER"}
+
+func (view userView) row() []string {
+	return []string{view.Username, view.FullName, view.Email, view.Role, view.Tier}
+}
+
+func (cli *CLI) createUser(inv *invocation) error {
+	username := inv.flags.String("username", "", "username of the new user")
+	fullName := inv.flags.String("full-name", "", "full name of the new user")
+	email := inv.flags.String("email", "", "email of the new user")
+	password := inv.flags.String("password", "", "password of the new user; read from standard input when omitted")
+
+	if err := inv.parse(); err != nil {
+		return err
+	}
+
+	for name, value := range map[string]string{"username": *username, "full-name": *fullName, "email": *email} {
+		if value == "" {
+			return inv.required(name)
+		}
+	}
+
+	plain, err := cli.readPassword(*password)
+
+	if err != nil {
+		return err
+	}
+
+	if err := cli.passwordPolicy.Validate(plain, *username, *email); err != nil {
+		return err
+	}
+
+	_, err = cli.store.GetUser(inv.ctx, *username)
+
+	if err == nil {
+		return fmt.Errorf("user %s already exists", *username)
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	action := fmt.Sprintf("create user %s", *username)
+
+	if inv.dryRun {
+		view := userView{Username: *username, FullName: *fullName, Email: *email}
+		return cli.printResult(inv, action, view, userHeader, view.row())
+	}
+
+	hashedPassword, err := cli.passwordHasher.HashPassword(plain)
+
+	if err != nil {
+		return err
+	}
+
+	user, err := cli.store.CreateUser(inv.ctx, db.CreateUserParams{
+		Username: *username,
+		HashedPassword: hashedPassword,
+		FullName: *fullName,
+		Email: *email,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	view := newUserView(user)
+
+	return cli.printResult(inv, action, view, userHeader, view.row())
+}
+
+func (cli *CLI) resetPassword(inv *invocation) error {
+	username := inv.flags.String("username", "", "user whose password is reset")
+	password := inv.flags.String("password", "", "new password; read from standard input when omitted")
+
+	if err := inv.parse(); err != nil {
+		return err
+	}
+
+	if *username == "" {
+		return inv.required("username")
+	}
+
+	user, err := cli.store.GetUser(inv.ctx, *username)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %s not found", *username)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	plain, err := cli.readPassword(*password)
+
+	if err != nil {
+		return err
+	}
+
+	if err := cli.passwordPolicy.Validate(plain, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	action := fmt.Sprintf("reset the password of %s and block their sessions", user.Username)
+
+	if !inv.dryRun {
+		hashedPassword, err := cli.passwordHasher.HashPassword(plain)
+
+		if err != nil {
+			return err
+		}
+
+		result, err := cli.store.ResetPasswordTx(inv.ctx, db.ResetPasswordTxParams{
+			Username: user.Username,
+			HashedPassword: hashedPassword,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		user = result.User
+		action = fmt.Sprintf("%s (%d blocked)", action, result.BlockedSessions)
+	}
+
+	view := newUserView(user)
+
+	return cli.printResult(inv, action, view, userHeader, view.row())
+}
+
+func (cli *CLI) setRole(inv *invocation) error {
+	username := inv.flags.String("username", "", "user whose role is set")
+	role := inv.flags.String("role", "", fmt.Sprintf("new role, %s or %s", util.CustomerRole, util.AdminRole))
+
+	if err := inv.parse(); err != nil {
+		return err
+	}
+
+	for name, value := range map[string]string{"username": *username, "role": *role} {
+		if value == "" {
+			return inv.required(name)
+		}
+	}
+
+	if *role != util.CustomerRole && *role != util.AdminRole {
+		return fmt.Errorf("unknown role %s: must be %s or %s", *role, util.CustomerRole, util.AdminRole)
+	}
+
+	user, err := cli.store.GetUser(inv.ctx, *username)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %s not found", *username)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	action := fmt.Sprintf("set the role of %s to %s", user.Username, *role)
+
+	if inv.dryRun {
+		user.Role = *role
+	} else {
+		user, err = cli.store.UpdateUserRole(inv.ctx, db.UpdateUserRoleParams{
+			Username: user.Username,
+			Role: *role,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	view := newUserView(user)
+
+	return cli.printResult(inv, action, view, userHeader, view.row())
+}
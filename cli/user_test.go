@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemStore()
+
+	user, err := store.CreateUser(ctx, db.CreateUserParams{
+		Username: "alice",
+		HashedPassword: util.RandomString(32),
+		FullName: "Alice",
+		Email: "alice@example.com",
+	})
+	require.NoError(t, err)
+
+	session, err := store.CreateSession(ctx, db.CreateSessionParams{
+		ID: uuid.New(),
+		Username: user.Username,
+		RefreshToken: util.RandomString(32),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	cli, stdout := newTestCLI(t, store, testPassword+"\n")
+
+	// a dry run changes nothing
+	require.NoError(t, cli.Run(ctx, []string{"user", "reset-password", "--username", "alice", "--dry-run"}))
+
+	unchanged, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	require.False(t, unchanged.IsBlocked)
+
+	time.Sleep(time.Millisecond)
+	require.NoError(t, cli.Run(ctx, []string{"user", "reset-password", "--username", "alice", "--password", testPassword}))
+	require.Contains(t, stdout.String(), "reset the password of alice and block their sessions (1 blocked): done")
+
+	reset, err := store.GetUser(ctx, user.Username)
+	require.NoError(t, err)
+	require.NoError(t, util.CheckPassword(testPassword, reset.HashedPassword))
+	require.True(t, reset.PasswordChangedAt.After(user.PasswordChangedAt))
+
+	// the refresh token issued before the reset no longer renews access
+	blocked, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+}
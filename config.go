@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mateusribs/simple_bank/cli"
+	"github.com/mateusribs/simple_bank/util"
+)
+
+// prints the settings after every layer was applied, as KEY=value lines or a JSON object;
+// secrets are masked, which --redacted asks for explicitly, unless --show-secrets is given
+func runConfig(config util.Config, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: simplebank config print [--redacted | --show-secrets] [--json]")
+		return cli.ErrUsage
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redacted := flags.Bool("redacted", true, "mask the secrets, which is the default")
+	showSecrets := flags.Bool("show-secrets", false, "print the secrets, such as the token key and the database password, instead of masking them")
+	asJSON := flags.Bool("json", false, "print a JSON object instead of KEY=value lines")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return cli.ErrUsage
+	}
+
+	// an explicit --redacted must agree with --show-secrets, so --redacted=false alone cannot print secrets
+	var explicit bool
+	flags.Visit(func(f *flag.Flag) { explicit = explicit || f.Name == "redacted" })
+	if explicit && *redacted == *showSecrets {
+		fmt.Fprintln(os.Stderr, "config print: --redacted and --show-secrets conflict")
+		return cli.ErrUsage
+	}
+
+	entries := config.Entries(!*showSecrets)
+
+	if *asJSON {
+		settings := make(map[string]string, len(entries))
+
+		for _, entry := range entries {
+			settings[entry[0]] = entry[1]
+		}
+
+		return json.NewEncoder(os.Stdout).Encode(settings)
+	}
+
+	for _, entry := range entries {
+		fmt.Printf("%s=%s\n", entry[0], entry[1])
+	}
+
+	// the printed settings may still be unusable; say so without failing, so broken ones can be inspected
+	if err := config.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "\ninvalid config:\n%s\n", err)
+	}
+
+	return nil
+}
+
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "transfer_limits";
+
+DROP INDEX IF EXISTS "entries_account_id_created_at_idx";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "tier";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,25 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'customer';
+
+ALTER TABLE "users" ADD COLUMN "tier" varchar NOT NULL DEFAULT 'standard';
+
+CREATE TABLE "transfer_limits" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint UNIQUE,
+  "tier" varchar UNIQUE,
+  "max_per_transfer" bigint NOT NULL DEFAULT 0,
+  "max_daily_amount" bigint NOT NULL DEFAULT 0,
+  "max_daily_count" integer NOT NULL DEFAULT 0,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "account_or_tier" CHECK (("account_id" IS NULL) <> ("tier" IS NULL))
+);
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+COMMENT ON COLUMN "transfer_limits"."max_per_transfer" IS '0 means unlimited';
+
+COMMENT ON COLUMN "transfer_limits"."max_daily_amount" IS 'rolling 24 hours, 0 means unlimited';
+
+COMMENT ON COLUMN "transfer_limits"."max_daily_count" IS 'rolling 24 hours, 0 means unlimited';
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "transfer_challenges";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "totp_secret";
@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "type";
+
+DROP TABLE IF EXISTS "fee_schedules";
@@ -0,0 +1,24 @@
+CREATE TABLE "fee_schedules" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "percentage_bps" integer NOT NULL DEFAULT 0,
+  "revenue_account_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "currency_min_amount_key" UNIQUE ("currency", "min_amount")
+);
+
+COMMENT ON COLUMN "fee_schedules"."min_amount" IS 'applies from this transfer amount up to the next tier';
+
+COMMENT ON COLUMN "fee_schedules"."percentage_bps" IS 'basis points of the amount, 100 is 1%';
+
+ALTER TABLE "fee_schedules" ADD FOREIGN KEY ("revenue_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "entries" ADD COLUMN "type" varchar NOT NULL DEFAULT 'transfer';
+
+COMMENT ON COLUMN "entries"."type" IS 'transfer or fee';
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "transfers"."fee" IS 'charged to the sender on top of the amount';
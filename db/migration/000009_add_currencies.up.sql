@@ -0,0 +1,18 @@
+CREATE TABLE "currencies" (
+  "code" varchar PRIMARY KEY,
+  "exponent" integer NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT true,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 code';
+
+COMMENT ON COLUMN "currencies"."exponent" IS 'digits of the minor unit';
+
+COMMENT ON COLUMN "currencies"."enabled" IS 'disabled currencies cannot be used for new accounts or transfers';
+
+INSERT INTO "currencies" ("code", "exponent") VALUES
+  ('USD', 2),
+  ('EUR', 2),
+  ('BRL', 2);
@@ -0,0 +1,3 @@
+ALTER TABLE "accounts" ADD COLUMN "is_frozen" boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "accounts"."is_frozen" IS 'frozen accounts cannot send or receive transfers or place holds';
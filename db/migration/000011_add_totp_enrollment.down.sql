@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "totp_last_counter";
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "totp_pending_secret";
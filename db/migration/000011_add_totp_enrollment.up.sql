@@ -0,0 +1,5 @@
+ALTER TABLE "users" ADD COLUMN "totp_pending_secret" varchar NOT NULL DEFAULT '';
+ALTER TABLE "users" ADD COLUMN "totp_last_counter" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "users"."totp_pending_secret" IS 'an enrolled secret not yet confirmed with a code, encrypted like totp_secret';
+COMMENT ON COLUMN "users"."totp_last_counter" IS 'time step of the last accepted TOTP code, codes of this step or earlier are replays';
@@ -0,0 +1,111 @@
+package migration
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// the SQL files of this directory, compiled into the binary
+//
+//go:embed *.sql
+var files embed.FS
+
+// a migrator for the embedded files; the pgx driver holds an advisory lock while migrating,
+// so replicas starting together apply each migration once
+func New(dbSource string) (*migrate.Migrate, error) {
+	source, err := iofs.New(files, ".")
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read embedded migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, databaseURL(dbSource))
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create migrator: %w", err)
+	}
+
+	m.Log = migrateLogger{}
+
+	return m, nil
+}
+
+// golang-migrate picks its driver by the URL scheme, and its pgx driver registers as pgx5
+func databaseURL(dbSource string) string {
+	for _, scheme := range []string{"postgresql://", "postgres://"} {
+		if rest, ok := strings.CutPrefix(dbSource, scheme); ok {
+			return "pgx5://" + rest
+		}
+	}
+
+	return dbSource
+}
+
+// applies every pending migration
+func Up(dbSource string) (err error) {
+	m, err := New(dbSource)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if sourceErr, dbErr := m.Close(); err == nil {
+			err = errors.Join(sourceErr, dbErr)
+		}
+	}()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	return nil
+}
+
+// the highest version among the embedded migrations
+func LatestVersion() int64 {
+	names, err := fs.Glob(files, "*.up.sql")
+
+	if err != nil {
+		panic(err)
+	}
+
+	var latest int64
+
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(name, "_")
+
+		version, err := strconv.ParseInt(prefix, 10, 64)
+
+		if err != nil {
+			panic(fmt.Sprintf("migration %s has no version prefix", name))
+		}
+
+		if version > latest {
+			latest = version
+		}
+	}
+
+	return latest
+}
+
+// writes the progress of golang-migrate to the service log
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...interface{}) {
+	log.Info().Msg(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (migrateLogger) Verbose() bool {
+	return log.Logger.GetLevel() <= zerolog.DebugLevel
+}
@@ -2,9 +2,11 @@
 INSERT INTO accounts (
     owner,
     balance,
-    currency
+    available_balance,
+    currency,
+    product
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetAccount :one
@@ -26,16 +28,50 @@ OFFSET $3;
 
 -- name: UpdateAccount :one
 UPDATE accounts
-SET balance = $3
+SET balance = $3,
+    available_balance = available_balance + ($3 - balance)
 WHERE id = $1 AND owner = $2
 RETURNING *;
 
 -- name: AddAccountBalance :one
 UPDATE accounts
-SET balance = balance + sqlc.arg(amount)
+SET balance = balance + sqlc.arg(amount),
+    available_balance = available_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: AddAccountAvailableBalance :one
+UPDATE accounts
+SET available_balance = available_balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: SetAccountFrozen :one
+UPDATE accounts
+SET is_frozen = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ReconcileAccounts :many
+SELECT
+    a.id,
+    a.owner,
+    a.currency,
+    a.balance,
+    COALESCE(e.total, 0)::bigint AS ledger_balance,
+    a.available_balance,
+    (a.balance - COALESCE(h.total, 0))::bigint AS expected_available_balance
+FROM accounts a
+LEFT JOIN (
+    SELECT account_id, SUM(amount) AS total FROM entries GROUP BY account_id
+) e ON e.account_id = a.id
+LEFT JOIN (
+    SELECT from_account_id, SUM(amount) AS total FROM holds WHERE status = 'authorized' GROUP BY from_account_id
+) h ON h.from_account_id = a.id
+WHERE a.balance <> COALESCE(e.total, 0)
+OR a.available_balance <> a.balance - COALESCE(h.total, 0)
+ORDER BY a.id;
@@ -0,0 +1,20 @@
+-- name: GetCurrency :one
+SELECT * FROM currencies
+WHERE code = $1 LIMIT 1;
+
+-- name: ListCurrencies :many
+SELECT * FROM currencies
+ORDER BY code;
+
+-- name: UpsertCurrency :one
+INSERT INTO currencies (
+    code,
+    exponent,
+    enabled
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT (code) DO UPDATE
+SET exponent = EXCLUDED.exponent,
+    enabled = EXCLUDED.enabled,
+    updated_at = now()
+RETURNING *;
@@ -1,9 +1,10 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
     account_id,
-    amount
+    amount,
+    type
 ) VALUES (
-    $1, $2
+    $1, $2, $3
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -24,4 +25,24 @@ RETURNING *;
 
 -- name: DeleteEntry :exec
 DELETE FROM entries
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: GetDailyOutgoingUsage :one
+SELECT
+    COALESCE(SUM(-amount), 0)::bigint AS total_amount,
+    COUNT(*) AS transfer_count
+FROM entries
+WHERE account_id = $1
+AND amount < 0
+AND type = 'transfer'
+AND created_at > now() - interval '24 hours';
+
+-- name: ListLedgerEntries :many
+SELECT e.id, e.account_id, a.currency, e.amount, e.type, e.created_at
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+WHERE e.created_at >= sqlc.arg(from_time)
+AND e.created_at < sqlc.arg(to_time)
+AND e.id > sqlc.arg(after_id)
+ORDER BY e.id
+LIMIT sqlc.arg(page_size);
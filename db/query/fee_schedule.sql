@@ -0,0 +1,25 @@
+-- name: CreateFeeSchedule :one
+INSERT INTO fee_schedules (
+    currency,
+    min_amount,
+    flat_fee,
+    percentage_bps,
+    revenue_account_id
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetFeeSchedule :one
+SELECT * FROM fee_schedules
+WHERE currency = $1
+AND min_amount <= sqlc.arg(amount)
+ORDER BY min_amount DESC
+LIMIT 1;
+
+-- name: ListFeeSchedules :many
+SELECT * FROM fee_schedules
+ORDER BY currency, min_amount;
+
+-- name: DeleteFeeSchedule :exec
+DELETE FROM fee_schedules
+WHERE id = $1;
@@ -0,0 +1,35 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+    from_account_id,
+    to_account_id,
+    amount,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: UpdateHold :one
+UPDATE holds
+SET status = $2,
+    captured_amount = $3,
+    transfer_id = $4,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListExpiredHoldsForUpdate :many
+SELECT * FROM holds
+WHERE status = 'authorized'
+AND expires_at < now()
+ORDER BY from_account_id DESC, id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
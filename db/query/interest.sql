@@ -0,0 +1,96 @@
+-- name: ListInterestBearingAccounts :many
+SELECT accounts.id, accounts.currency, products.annual_rate_bps FROM accounts
+JOIN products ON products.code = accounts.product
+WHERE products.annual_rate_bps > 0
+AND accounts.created_at < sqlc.arg(before)::timestamptz
+ORDER BY accounts.id;
+
+-- name: GetBalanceAt :one
+SELECT (accounts.balance - COALESCE((
+    SELECT SUM(entries.amount) FROM entries
+    WHERE entries.account_id = accounts.id
+    AND entries.created_at >= sqlc.arg(at)::timestamptz
+), 0))::bigint AS balance
+FROM accounts
+WHERE accounts.id = sqlc.arg(account_id);
+
+-- name: CreateInterestAccrual :execrows
+INSERT INTO interest_accruals (
+    account_id,
+    accrual_date,
+    balance,
+    annual_rate_bps,
+    amount_micros
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (account_id, accrual_date) DO NOTHING;
+
+-- name: GetLastInterestAccrualDate :one
+SELECT COALESCE(MAX(accrual_date), '0001-01-01')::date AS last_accrual_date FROM interest_accruals;
+
+-- name: ListAccountsWithUnpostedInterest :many
+SELECT DISTINCT account_id FROM interest_accruals
+WHERE posting_id IS NULL
+AND accrual_date < sqlc.arg(period_end)
+ORDER BY account_id;
+
+-- name: ListUnpostedAccrualsForUpdate :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+AND posting_id IS NULL
+AND accrual_date < sqlc.arg(period_end)
+ORDER BY accrual_date
+FOR UPDATE;
+
+-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+    account_id,
+    expense_account_id,
+    period_end,
+    accrued_micros,
+    amount,
+    entry_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: MarkAccrualsPosted :exec
+UPDATE interest_accruals
+SET posting_id = sqlc.arg(posting_id)
+WHERE account_id = sqlc.arg(account_id)
+AND posting_id IS NULL
+AND accrual_date < sqlc.arg(period_end);
+
+-- name: GetInterestExpenseAccount :one
+SELECT account_id FROM interest_expense_accounts
+WHERE currency = $1 LIMIT 1;
+
+-- name: SetInterestExpenseAccount :one
+INSERT INTO interest_expense_accounts (
+    currency,
+    account_id
+) VALUES (
+    $1, $2
+) ON CONFLICT (currency) DO UPDATE
+SET account_id = EXCLUDED.account_id
+RETURNING *;
+
+-- name: GetInterestAccrualReport :many
+SELECT
+    interest_accruals.account_id,
+    accounts.currency,
+    COUNT(*) AS days,
+    SUM(interest_accruals.amount_micros)::bigint AS accrued_micros,
+    COALESCE(SUM(interest_accruals.amount_micros) FILTER (WHERE interest_accruals.posting_id IS NOT NULL), 0)::bigint AS posted_micros
+FROM interest_accruals
+JOIN accounts ON accounts.id = interest_accruals.account_id
+WHERE interest_accruals.accrual_date >= sqlc.arg(from_date)
+AND interest_accruals.accrual_date < sqlc.arg(to_date)
+GROUP BY interest_accruals.account_id, accounts.currency
+ORDER BY interest_accruals.account_id;
+
+-- name: ListInterestPostings :many
+SELECT * FROM interest_postings
+WHERE period_end > sqlc.arg(from_date)
+AND period_end <= sqlc.arg(to_date)
+ORDER BY id;
@@ -0,0 +1,26 @@
+-- name: CreateTransferChallenge :one
+INSERT INTO transfer_challenges (
+    id,
+    username,
+    from_account_id,
+    to_account_id,
+    amount,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetTransferChallenge :one
+SELECT * FROM transfer_challenges
+WHERE id = $1 LIMIT 1;
+
+-- name: GetTransferChallengeForUpdate :one
+SELECT * FROM transfer_challenges
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: CompleteTransferChallenge :one
+UPDATE transfer_challenges
+SET status = 'completed', transfer_id = $2
+WHERE id = $1
+RETURNING *;
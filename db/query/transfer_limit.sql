@@ -0,0 +1,40 @@
+-- name: UpsertAccountTransferLimit :one
+INSERT INTO transfer_limits (
+    account_id,
+    max_per_transfer,
+    max_daily_amount,
+    max_daily_count
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT (account_id) DO UPDATE
+SET max_per_transfer = EXCLUDED.max_per_transfer,
+    max_daily_amount = EXCLUDED.max_daily_amount,
+    max_daily_count = EXCLUDED.max_daily_count,
+    updated_at = now()
+RETURNING *;
+
+-- name: UpsertTierTransferLimit :one
+INSERT INTO transfer_limits (
+    tier,
+    max_per_transfer,
+    max_daily_amount,
+    max_daily_count
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT (tier) DO UPDATE
+SET max_per_transfer = EXCLUDED.max_per_transfer,
+    max_daily_amount = EXCLUDED.max_daily_amount,
+    max_daily_count = EXCLUDED.max_daily_count,
+    updated_at = now()
+RETURNING *;
+
+-- name: GetEffectiveTransferLimit :one
+SELECT transfer_limits.* FROM transfer_limits
+WHERE transfer_limits.account_id = sqlc.arg(account_id)::bigint
+   OR transfer_limits.tier = (
+    SELECT users.tier FROM accounts
+    JOIN users ON users.username = accounts.owner
+    WHERE accounts.id = sqlc.arg(account_id)::bigint
+)
+ORDER BY transfer_limits.account_id IS NULL
+LIMIT 1;
@@ -10,4 +10,52 @@ INSERT INTO users (
 
 -- name: GetUser :one
 SELECT * FROM users
-WHERE username = $1 LIMIT 1;
\ No newline at end of file
+WHERE username = $1 LIMIT 1;
+
+-- name: UpdateUserHashedPassword :one
+UPDATE users
+SET hashed_password = $2
+WHERE username = $1
+RETURNING *;
+
+-- name: ResetUserPassword :one
+UPDATE users
+SET hashed_password = $2, password_changed_at = now()
+WHERE username = $1
+RETURNING *;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE username = $1
+RETURNING *;
+
+-- name: UpdateUserTier :one
+UPDATE users
+SET tier = $2
+WHERE username = $1
+RETURNING *;
+
+-- name: UpdateUserTOTPSecret :one
+UPDATE users
+SET totp_secret = $2
+WHERE username = $1
+RETURNING *;
+
+-- name: UpdateUserTOTPPendingSecret :one
+UPDATE users
+SET totp_pending_secret = $2
+WHERE username = $1
+RETURNING *;
+
+-- name: ConfirmUserTOTPSecret :one
+UPDATE users
+SET totp_secret = totp_pending_secret, totp_pending_secret = '', totp_last_counter = $3
+WHERE username = $1 AND totp_pending_secret = $2
+RETURNING *;
+
+-- name: UseUserTOTPCounter :one
+UPDATE users
+SET totp_last_counter = $2
+WHERE username = $1 AND totp_last_counter < $2
+RETURNING *;
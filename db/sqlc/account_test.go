@@ -17,6 +17,7 @@ func createRandomAccount(t *testing.T) Account {
 		Owner: user.Username,
 		Balance: util.RandomMoney(),
 		Currency: util.RandomCurrency(),
+		Product: ProductChecking,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -26,7 +27,9 @@ func createRandomAccount(t *testing.T) Account {
 
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
+	require.Equal(t, arg.Balance, account.AvailableBalance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.Product, account.Product)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
@@ -34,6 +37,21 @@ func createRandomAccount(t *testing.T) Account {
 	return account
 }
 
+// creates an account in the currency with the given balance
+func createCurrencyAccount(t *testing.T, currency string, balance int64) Account {
+	user := createRandomUser(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner: user.Username,
+		Balance: balance,
+		Currency: currency,
+		Product: ProductChecking,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
 func TestCreateAccount(t *testing.T) {
 	createRandomAccount(t)
 }
@@ -109,4 +127,17 @@ func TestListAccounts(t *testing.T){
 		require.NotEmpty(t, account)
 		require.Equal(t, lastAccount.Owner, account.Owner)
 	}
-}
\ No newline at end of file
+}
+
+func TestSetAccountFrozen(t *testing.T) {
+	account := createRandomAccount(t)
+	require.False(t, account.IsFrozen)
+
+	frozen, err := testQueries.SetAccountFrozen(context.Background(), SetAccountFrozenParams{ID: account.ID, IsFrozen: true})
+	require.NoError(t, err)
+	require.True(t, frozen.IsFrozen)
+
+	unfrozen, err := testQueries.SetAccountFrozen(context.Background(), SetAccountFrozenParams{ID: account.ID, IsFrozen: false})
+	require.NoError(t, err)
+	require.False(t, unfrozen.IsFrozen)
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	ChallengePending   = "pending"
+	ChallengeCompleted = "completed"
+)
+
+var (
+	ErrChallengeExpired   = errors.New("transfer challenge has expired")
+	ErrChallengeCompleted = errors.New("transfer challenge is already completed")
+)
+
+// runs the transfer held by a pending challenge and marks the challenge as completed within a single database transaction
+func (store *txStore) CompleteTransferChallengeTx(ctx context.Context, challengeID uuid.UUID) (TransferTxResult, error) {
+	var result TransferTxResult
+	start := time.Now()
+
+	ctx, span := startSpan(ctx, "CompleteTransferChallengeTx", attribute.String("transfer_challenge.id", challengeID.String()))
+
+	err := store.execTx(ctx, pgx.TxOptions{}, func(q Querier) error {
+		challenge, err := q.GetTransferChallengeForUpdate(ctx, challengeID)
+
+		if err != nil {
+			return err
+		}
+
+		if challenge.Status != ChallengePending {
+			return ErrChallengeCompleted
+		}
+
+		if time.Now().After(challenge.ExpiresAt) {
+			return ErrChallengeExpired
+		}
+
+		span.SetAttributes(fromAccountIDKey.Int64(challenge.FromAccountID), toAccountIDKey.Int64(challenge.ToAccountID))
+
+		result, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: challenge.FromAccountID,
+			ToAccountID: challenge.ToAccountID,
+			Amount: challenge.Amount,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CompleteTransferChallenge(ctx, CompleteTransferChallengeParams{
+			ID: challengeID,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+
+		return err
+	})
+
+	endSpan(span, err)
+	observeTransfer(start, result, err)
+
+	return result, err
+}
@@ -0,0 +1,363 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLStoreConformance(t *testing.T) {
+	testStoreConformance(t, NewStore(testDB, StoreOptions{}))
+}
+
+func TestMemStoreConformance(t *testing.T) {
+	testStoreConformance(t, NewMemStore())
+}
+
+// the behaviour callers rely on from every Store, so the in-memory store can stand in for Postgres
+func testStoreConformance(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	t.Run("UniqueOwnerCurrency", func(t *testing.T) {
+		account := createStoreAccount(t, store, util.RandomMoney())
+
+		_, err := store.CreateAccount(ctx, CreateAccountParams{
+			Owner: account.Owner,
+			Currency: account.Currency,
+			Product: ProductChecking,
+		})
+		requirePgError(t, err, pgerrcode.UniqueViolation, "owner_currency_key")
+	})
+
+	t.Run("UniqueUser", func(t *testing.T) {
+		user := createStoreUser(t, store)
+
+		_, err := store.CreateUser(ctx, CreateUserParams{
+			Username: util.RandomOwner(),
+			HashedPassword: user.HashedPassword,
+			FullName: user.FullName,
+			Email: user.Email,
+		})
+		requirePgError(t, err, pgerrcode.UniqueViolation, "users_email_key")
+	})
+
+	t.Run("ForeignKeys", func(t *testing.T) {
+		_, err := store.CreateAccount(ctx, CreateAccountParams{
+			Owner: util.RandomOwner(),
+			Currency: util.RandomCurrency(),
+			Product: ProductChecking,
+		})
+		requirePgError(t, err, pgerrcode.ForeignKeyViolation, "accounts_owner_fkey")
+
+		account := createStoreAccount(t, store, util.RandomMoney())
+
+		_, err = store.CreateEntry(ctx, CreateEntryParams{AccountID: account.ID + 1_000_000, Amount: 10, Type: EntryTypeTransfer})
+		requirePgError(t, err, pgerrcode.ForeignKeyViolation, "entries_account_id_fkey")
+
+		_, err = store.CreateEntry(ctx, CreateEntryParams{AccountID: account.ID, Amount: 10, Type: EntryTypeTransfer})
+		require.NoError(t, err)
+
+		err = store.DeleteAccount(ctx, account.ID)
+		requirePgError(t, err, pgerrcode.ForeignKeyViolation, "entries_account_id_fkey")
+	})
+
+	t.Run("NoRows", func(t *testing.T) {
+		account := createStoreAccount(t, store, util.RandomMoney())
+		require.NoError(t, store.DeleteAccount(ctx, account.ID))
+
+		_, err := store.GetAccount(ctx, account.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		_, err = store.GetUser(ctx, util.RandomOwner())
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		_, err = store.UpdateAccount(ctx, UpdateAccountParams{ID: account.ID, Owner: account.Owner, Balance: 10})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("ListAccounts", func(t *testing.T) {
+		user := createStoreUser(t, store)
+		accounts := []Account{}
+
+		for _, currency := range util.DefaultCurrencies {
+			account, err := store.CreateAccount(ctx, CreateAccountParams{
+				Owner: user.Username,
+				Balance: util.RandomMoney(),
+				Currency: currency,
+				Product: ProductChecking,
+			})
+			require.NoError(t, err)
+
+			accounts = append(accounts, account)
+		}
+
+		page, err := store.ListAccounts(ctx, ListAccountsParams{Owner: user.Username, Limit: 2, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		require.Equal(t, accounts[1].ID, page[0].ID)
+		require.Equal(t, accounts[2].ID, page[1].ID)
+	})
+
+	t.Run("TransferTx", func(t *testing.T) {
+		account1 := createStoreAccount(t, store, 1000)
+		account2, err := store.CreateAccount(ctx, CreateAccountParams{
+			Owner: createStoreUser(t, store).Username,
+			Balance: 1000,
+			Currency: account1.Currency,
+			Product: ProductChecking,
+		})
+		require.NoError(t, err)
+
+		result, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 100})
+		require.NoError(t, err)
+		require.Equal(t, int64(100), result.Transfer.Amount)
+		require.Equal(t, int64(-100), result.FromEntry.Amount)
+		require.Equal(t, int64(100), result.ToEntry.Amount)
+		require.Equal(t, 1000-result.TotalDebit.Amount, result.FromAccount.Balance)
+		require.Equal(t, int64(1100), result.ToAccount.Balance)
+
+		updated, err := store.GetAccount(ctx, account2.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(1100), updated.Balance)
+		require.Equal(t, int64(1100), updated.AvailableBalance)
+
+		_, err = store.GetTransfer(ctx, result.Transfer.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("FrozenAccount", func(t *testing.T) {
+		account1 := createStoreAccount(t, store, 1000)
+		account2, err := store.CreateAccount(ctx, CreateAccountParams{
+			Owner: createStoreUser(t, store).Username,
+			Balance: 1000,
+			Currency: account1.Currency,
+			Product: ProductChecking,
+		})
+		require.NoError(t, err)
+
+		_, err = store.SetAccountFrozen(ctx, SetAccountFrozenParams{ID: account2.ID, IsFrozen: true})
+		require.NoError(t, err)
+
+		_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 100})
+		require.ErrorIs(t, err, ErrAccountFrozen)
+
+		// a frozen account cannot be the payee of a hold either
+		_, err = store.AuthorizeHoldTx(ctx, AuthorizeHoldTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID: account2.ID,
+			Amount: 100,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.ErrorIs(t, err, ErrAccountFrozen)
+
+		for _, account := range []Account{account1, account2} {
+			unchanged, err := store.GetAccount(ctx, account.ID)
+			require.NoError(t, err)
+			require.Equal(t, account.Balance, unchanged.Balance)
+		}
+	})
+
+	t.Run("TransferTxConcurrent", func(t *testing.T) {
+		account1 := createStoreAccount(t, store, 1000)
+		account2, err := store.CreateAccount(ctx, CreateAccountParams{
+			Owner: createStoreUser(t, store).Username,
+			Balance: 1000,
+			Currency: account1.Currency,
+			Product: ProductChecking,
+		})
+		require.NoError(t, err)
+
+		n := 10
+		errs := make(chan error)
+
+		for i := 0; i < n; i++ {
+			fromAccountID, toAccountID := account1.ID, account2.ID
+
+			if i%2 == 1 {
+				fromAccountID, toAccountID = toAccountID, fromAccountID
+			}
+
+			go func() {
+				_, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: fromAccountID, ToAccountID: toAccountID, Amount: 10})
+				errs <- err
+			}()
+		}
+
+		for i := 0; i < n; i++ {
+			require.NoError(t, <-errs)
+		}
+
+		for _, account := range []Account{account1, account2} {
+			updated, err := store.GetAccount(ctx, account.ID)
+			require.NoError(t, err)
+			require.Equal(t, account.Balance, updated.Balance)
+		}
+	})
+
+	t.Run("Holds", func(t *testing.T) {
+		account1 := createStoreAccount(t, store, 1000)
+		account2, err := store.CreateAccount(ctx, CreateAccountParams{
+			Owner: createStoreUser(t, store).Username,
+			Currency: account1.Currency,
+			Product: ProductChecking,
+		})
+		require.NoError(t, err)
+
+		authorized, err := store.AuthorizeHoldTx(ctx, AuthorizeHoldTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID: account2.ID,
+			Amount: 300,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		require.Equal(t, HoldAuthorized, authorized.Hold.Status)
+		require.Equal(t, int64(1000), authorized.FromAccount.Balance)
+		require.Equal(t, int64(700), authorized.FromAccount.AvailableBalance)
+
+		_, err = store.AuthorizeHoldTx(ctx, AuthorizeHoldTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID: account2.ID,
+			Amount: 800,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+
+		voided, err := store.VoidHoldTx(ctx, authorized.Hold.ID)
+		require.NoError(t, err)
+		require.Equal(t, HoldVoided, voided.Status)
+
+		updated, err := store.GetAccount(ctx, account1.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), updated.AvailableBalance)
+	})
+
+	t.Run("TOTPEnrollment", func(t *testing.T) {
+		user := createStoreUser(t, store)
+
+		_, err := store.UpdateUserTOTPPendingSecret(ctx, UpdateUserTOTPPendingSecretParams{Username: user.Username, TotpPendingSecret: "first"})
+		require.NoError(t, err)
+
+		_, err = store.UpdateUserTOTPPendingSecret(ctx, UpdateUserTOTPPendingSecretParams{Username: user.Username, TotpPendingSecret: "second"})
+		require.NoError(t, err)
+
+		// a newer enrollment replaced the pending secret the code was checked against
+		_, err = store.ConfirmUserTOTPSecret(ctx, ConfirmUserTOTPSecretParams{Username: user.Username, TotpPendingSecret: "first", TotpLastCounter: 100})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		confirmed, err := store.ConfirmUserTOTPSecret(ctx, ConfirmUserTOTPSecretParams{Username: user.Username, TotpPendingSecret: "second", TotpLastCounter: 100})
+		require.NoError(t, err)
+		require.Equal(t, "second", confirmed.TotpSecret)
+		require.Empty(t, confirmed.TotpPendingSecret)
+		require.Equal(t, int64(100), confirmed.TotpLastCounter)
+
+		// each time step is used once
+		_, err = store.UseUserTOTPCounter(ctx, UseUserTOTPCounterParams{Username: user.Username, TotpLastCounter: 100})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		used, err := store.UseUserTOTPCounter(ctx, UseUserTOTPCounterParams{Username: user.Username, TotpLastCounter: 101})
+		require.NoError(t, err)
+		require.Equal(t, int64(101), used.TotpLastCounter)
+
+		_, err = store.UseUserTOTPCounter(ctx, UseUserTOTPCounterParams{Username: user.Username, TotpLastCounter: 100})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("ResetPasswordTx", func(t *testing.T) {
+		user := createStoreUser(t, store)
+
+		for _, blocked := range []bool{false, false, true} {
+			_, err := store.CreateSession(ctx, CreateSessionParams{
+				ID: uuid.New(),
+				Username: user.Username,
+				RefreshToken: util.RandomString(32),
+				IsBlocked: blocked,
+				ExpiresAt: time.Now().Add(time.Hour),
+			})
+			require.NoError(t, err)
+		}
+
+		result, err := store.ResetPasswordTx(ctx, ResetPasswordTxParams{Username: user.Username, HashedPassword: "reset"})
+		require.NoError(t, err)
+		require.Equal(t, "reset", result.User.HashedPassword)
+		require.True(t, result.User.PasswordChangedAt.After(user.PasswordChangedAt))
+		require.Equal(t, int64(2), result.BlockedSessions)
+
+		_, err = store.ResetPasswordTx(ctx, ResetPasswordTxParams{Username: util.RandomOwner(), HashedPassword: "reset"})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("HoldThenTransferThenCapture", func(t *testing.T) {
+		account1 := createStoreAccount(t, store, 1000)
+		account2, err := store.CreateAccount(ctx, CreateAccountParams{
+			Owner: createStoreUser(t, store).Username,
+			Currency: account1.Currency,
+			Product: ProductChecking,
+		})
+		require.NoError(t, err)
+
+		authorized, err := store.AuthorizeHoldTx(ctx, AuthorizeHoldTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID: account2.ID,
+			Amount: 600,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		// the held money cannot be spent by a plain transfer
+		_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 500})
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+
+		_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 400})
+		require.NoError(t, err)
+
+		// the capture spends the money its own hold released
+		captured, err := store.CaptureHoldTx(ctx, CaptureHoldTxParams{HoldID: authorized.Hold.ID})
+		require.NoError(t, err)
+		require.Equal(t, int64(600), captured.Transfer.Amount)
+
+		updated, err := store.GetAccount(ctx, account1.ID)
+		require.NoError(t, err)
+		require.Zero(t, updated.Balance)
+		require.Zero(t, updated.AvailableBalance)
+	})
+}
+
+func createStoreUser(t *testing.T, store Store) User {
+	user, err := store.CreateUser(context.Background(), CreateUserParams{
+		Username: util.RandomOwner(),
+		HashedPassword: util.RandomString(32),
+		FullName: util.RandomOwner(),
+		Email: util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	return user
+}
+
+func createStoreAccount(t *testing.T, store Store, balance int64) Account {
+	account, err := store.CreateAccount(context.Background(), CreateAccountParams{
+		Owner: createStoreUser(t, store).Username,
+		Balance: balance,
+		Currency: util.RandomCurrency(),
+		Product: ProductChecking,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
+func requirePgError(t *testing.T, err error, code string, constraint string) {
+	var pgErr *pgconn.PgError
+
+	require.True(t, errors.As(err, &pgErr), "expected a *pgconn.PgError, got %v", err)
+	require.Equal(t, code, pgErr.Code)
+	require.Equal(t, constraint, pgErr.ConstraintName)
+}
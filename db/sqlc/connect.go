@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/rs/zerolog/log"
+)
+
+// delays between the pings of Open, doubling from the first up to the last
+const (
+	minConnectBackoff = 100 * time.Millisecond
+	maxConnectBackoff = 5 * time.Second
+)
+
+// opens the pool configured by the DB_ settings and pings until the database answers, backing off exponentially.
+// Gives up once DB_CONNECT_TIMEOUT passes or ctx is cancelled, so a service never starts without its database.
+func Open(ctx context.Context, config util.Config) (*pgxpool.Pool, error) {
+	return open(ctx, config, "DB_SOURCE", config.DBSource)
+}
+
+// opens the replica of DB_REPLICA_SOURCE like Open, with the same pool settings; nil when no replica is configured
+func OpenReplica(ctx context.Context, config util.Config) (*pgxpool.Pool, error) {
+	if config.DBReplicaSource == "" {
+		return nil, nil
+	}
+
+	return open(ctx, config, "DB_REPLICA_SOURCE", config.DBReplicaSource)
+}
+
+func open(ctx context.Context, config util.Config, key string, source string) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(source)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	// zero keeps the default of pgxpool, the larger of 4 and the number of CPUs
+	if config.DBMaxOpenConns > 0 {
+		poolConfig.MaxConns = int32(config.DBMaxOpenConns)
+	}
+
+	poolConfig.MinConns = int32(config.DBMinConns)
+	poolConfig.MaxConnLifetime = config.DBConnMaxLifetime
+	poolConfig.MaxConnIdleTime = config.DBConnMaxIdleTime
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DBConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.DBConnectTimeout)
+		defer cancel()
+	}
+
+	if err := ping(ctx, pool); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+func ping(ctx context.Context, pool *pgxpool.Pool) error {
+	backoff := minConnectBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := pool.Ping(ctx)
+
+		if err == nil {
+			return nil
+		}
+
+		log.Warn().Err(err).Int("attempt", attempt).Dur("retry_in", backoff).Msg("database is not reachable")
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("database is not reachable after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+
+		if backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
+	}
+}
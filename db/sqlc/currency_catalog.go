@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mateusribs/simple_bank/money"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/rs/zerolog/log"
+)
+
+// in-memory copy of the currencies table, so validating a request does not hit the database.
+// The HTTP and gRPC servers share one catalogue.
+type CurrencyCatalog struct {
+	mu sync.RWMutex
+	currencies map[string]Currency
+}
+
+// starts with the currencies seeded by the migrations until the first refresh
+func NewCurrencyCatalog() *CurrencyCatalog {
+	catalog := &CurrencyCatalog{currencies: map[string]Currency{}}
+
+	for _, code := range util.DefaultCurrencies {
+		currency, _ := money.Lookup(code)
+
+		catalog.currencies[code] = Currency{
+			Code: code,
+			Exponent: int32(currency.Exponent),
+			Enabled: true,
+		}
+	}
+
+	return catalog
+}
+
+// replaces the catalogue with the contents of the currencies table; a failure keeps the last contents
+func (catalog *CurrencyCatalog) Refresh(ctx context.Context, q Querier) error {
+	list, err := q.ListCurrencies(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	currencies := make(map[string]Currency, len(list))
+
+	for _, currency := range list {
+		currencies[currency.Code] = currency
+	}
+
+	catalog.mu.Lock()
+	catalog.currencies = currencies
+	catalog.mu.Unlock()
+
+	return nil
+}
+
+// refreshes the catalogue every interval until ctx is cancelled, so changes made through another instance show up
+func (catalog *CurrencyCatalog) Run(ctx context.Context, q Querier, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := catalog.Refresh(ctx, q); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("cannot refresh currencies")
+		}
+	}
+}
+
+func (catalog *CurrencyCatalog) Set(currency Currency) {
+	catalog.mu.Lock()
+	catalog.currencies[currency.Code] = currency
+	catalog.mu.Unlock()
+}
+
+func (catalog *CurrencyCatalog) IsEnabled(code string) bool {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	currency, ok := catalog.currencies[code]
+	return ok && currency.Enabled
+}
+
+// returns the enabled currencies ordered by code
+func (catalog *CurrencyCatalog) Enabled() []Currency {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	list := make([]Currency, 0, len(catalog.currencies))
+
+	for _, currency := range catalog.currencies {
+		if currency.Enabled {
+			list = append(list, currency)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+
+	return list
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeededCurrencies(t *testing.T) {
+	for _, code := range util.DefaultCurrencies {
+		currency, err := testQueries.GetCurrency(context.Background(), code)
+		require.NoError(t, err)
+		require.Equal(t, int32(2), currency.Exponent)
+	}
+}
+
+func TestUpsertCurrency(t *testing.T) {
+	code := randomUnusedCurrency()
+
+	currency, err := testQueries.UpsertCurrency(context.Background(), UpsertCurrencyParams{
+		Code: code,
+		Exponent: 2,
+		Enabled: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, code, currency.Code)
+	require.True(t, currency.Enabled)
+
+	updated, err := testQueries.UpsertCurrency(context.Background(), UpsertCurrencyParams{
+		Code: code,
+		Exponent: 2,
+		Enabled: false,
+	})
+	require.NoError(t, err)
+	require.False(t, updated.Enabled)
+	require.Equal(t, currency.CreatedAt, updated.CreatedAt)
+
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	var found bool
+
+	for _, c := range currencies {
+		if c.Code == code {
+			found = true
+			require.False(t, c.Enabled)
+		}
+	}
+	require.True(t, found)
+}
@@ -17,6 +17,7 @@ func createRandomEntry(t *testing.T) Entry {
 	arg := CreateEntryParams{
 		AccountID: account.ID,
 		Amount: util.RandomMoney(),
+		Type: EntryTypeTransfer,
 	}
 
 	entry, err := testQueries.CreateEntry(context.Background(), arg)
@@ -26,6 +27,7 @@ func createRandomEntry(t *testing.T) Entry {
 
 	require.Equal(t, arg.AccountID, entry.AccountID)
 	require.Equal(t, arg.Amount, entry.Amount)
+	require.Equal(t, arg.Type, entry.Type)
 
 	require.NotZero(t, entry.ID)
 	require.NotZero(t, entry.CreatedAt)
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/mateusribs/simple_bank/money"
+)
+
+const (
+	EntryTypeTransfer = "transfer"
+	EntryTypeFee      = "fee"
+)
+
+// fees charged on a transfer, on top of the transferred amount
+type FeeBreakdown struct {
+	ScheduleID       int64  `json:"schedule_id,omitempty"`
+	Currency         string `json:"currency"`
+	Amount           int64  `json:"amount"`
+	FlatFee          int64  `json:"flat_fee"`
+	PercentageFee    int64  `json:"percentage_fee"`
+	Total            int64  `json:"total"`
+	RevenueAccountID int64  `json:"-"`
+}
+
+// applies the schedule to the amount; the percentage part is rounded half up to the minor unit
+func CalculateFee(schedule FeeSchedule, amount int64) (FeeBreakdown, error) {
+	fee := FeeBreakdown{
+		ScheduleID:       schedule.ID,
+		Currency:         schedule.Currency,
+		Amount:           amount,
+		FlatFee:          schedule.FlatFee,
+		RevenueAccountID: schedule.RevenueAccountID,
+	}
+
+	scaled, err := money.Money{Amount: amount, Currency: schedule.Currency}.Mul(int64(schedule.PercentageBps))
+
+	if err != nil {
+		return fee, err
+	}
+
+	fee.PercentageFee = (scaled.Amount + 5000) / 10000
+
+	total, err := money.Money{Amount: fee.FlatFee, Currency: schedule.Currency}.Add(
+		money.Money{Amount: fee.PercentageFee, Currency: schedule.Currency},
+	)
+
+	fee.Total = total.Amount
+
+	return fee, err
+}
+
+// looks up the tier of the currency the amount falls into and calculates its fee.
+// Amounts without a schedule are free.
+func QuoteFee(ctx context.Context, q Querier, currency string, amount int64) (FeeBreakdown, error) {
+	schedule, err := q.GetFeeSchedule(ctx, GetFeeScheduleParams{
+		Currency: currency,
+		Amount:   amount,
+	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return FeeBreakdown{Currency: currency, Amount: amount}, nil
+		}
+		return FeeBreakdown{}, err
+	}
+
+	return CalculateFee(schedule, amount)
+}
+
+// quotes the fee of the transfer and locks every account it touches, the revenue account included,
+// failing if the payer or the payee is frozen. Also returns what the payer is debited in total, amount plus fee.
+func lockTransfer(ctx context.Context, q Querier, arg TransferTxParams) (FeeBreakdown, money.Money, error) {
+	var fee FeeBreakdown
+	var debit money.Money
+
+	fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+
+	if err != nil {
+		return fee, debit, err
+	}
+
+	toAccount, err := q.GetAccount(ctx, arg.ToAccountID)
+
+	if err != nil {
+		return fee, debit, err
+	}
+
+	if fromAccount.Currency != toAccount.Currency {
+		err = fmt.Errorf("%w: account [%d] holds %s, account [%d] holds %s", money.ErrCurrencyMismatch,
+			fromAccount.ID, fromAccount.Currency, toAccount.ID, toAccount.Currency)
+		return fee, debit, err
+	}
+
+	amount, err := money.New(arg.Amount, fromAccount.Currency)
+
+	if err != nil {
+		return fee, debit, err
+	}
+
+	fee, err = QuoteFee(ctx, q, amount.Currency, amount.Amount)
+
+	if err != nil {
+		return fee, debit, err
+	}
+
+	// the bank does not charge itself
+	if fee.RevenueAccountID == arg.FromAccountID {
+		fee = FeeBreakdown{Currency: fee.Currency, Amount: fee.Amount}
+	}
+
+	debit, err = amount.Add(money.Money{Amount: fee.Total, Currency: amount.Currency})
+
+	if err != nil {
+		return fee, debit, err
+	}
+
+	accountIDs := []int64{arg.FromAccountID, arg.ToAccountID}
+
+	if fee.Total > 0 {
+		accountIDs = append(accountIDs, fee.RevenueAccountID)
+	}
+
+	accounts, err := lockAccounts(ctx, q, accountIDs...)
+
+	if err != nil {
+		return fee, debit, err
+	}
+
+	return fee, debit, checkNotFrozen(accounts, arg.FromAccountID, arg.ToAccountID)
+}
+
+// moves the fee from the payer to the revenue account with its own pair of entries
+func chargeFee(ctx context.Context, q Querier, accountID int64, fee FeeBreakdown, result *TransferTxResult) error {
+	var err error
+
+	result.FeeEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: accountID,
+		Amount:    -fee.Total,
+		Type:      EntryTypeFee,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	result.RevenueEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: fee.RevenueAccountID,
+		Amount:    fee.Total,
+		Type:      EntryTypeFee,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	result.FromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     accountID,
+		Amount: -fee.Total,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	revenueAccount, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     fee.RevenueAccountID,
+		Amount: fee.Total,
+	})
+
+	if revenueAccount.ID == result.ToAccount.ID {
+		result.ToAccount = revenueAccount
+	}
+
+	return err
+}
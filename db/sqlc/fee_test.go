@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/mateusribs/simple_bank/money"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// picks an ISO 4217 currency other than those random accounts are opened in,
+// so fee schedules and interest accounts of a test stay isolated
+func randomUnusedCurrency() string {
+	currencies := money.Currencies()
+
+	for {
+		currency := currencies[util.RandomInt(0, int64(len(currencies)-1))].Code
+		used := false
+
+		for _, defaultCurrency := range util.DefaultCurrencies {
+			used = used || currency == defaultCurrency
+		}
+
+		if !used {
+			return currency
+		}
+	}
+}
+
+func createRandomFeeSchedule(t *testing.T, arg CreateFeeScheduleParams) FeeSchedule {
+	schedule, err := testQueries.CreateFeeSchedule(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, schedule.ID)
+	require.Equal(t, arg.Currency, schedule.Currency)
+	require.Equal(t, arg.MinAmount, schedule.MinAmount)
+	require.Equal(t, arg.FlatFee, schedule.FlatFee)
+	require.Equal(t, arg.PercentageBps, schedule.PercentageBps)
+	require.Equal(t, arg.RevenueAccountID, schedule.RevenueAccountID)
+
+	t.Cleanup(func() {
+		testQueries.DeleteFeeSchedule(context.Background(), schedule.ID)
+	})
+
+	return schedule
+}
+
+func TestCalculateFee(t *testing.T) {
+	schedule := FeeSchedule{ID: 1, Currency: util.USD, FlatFee: 25, PercentageBps: 150}
+
+	fee, err := CalculateFee(schedule, 1000)
+	require.NoError(t, err)
+	require.Equal(t, int64(25), fee.FlatFee)
+	require.Equal(t, int64(15), fee.PercentageFee)
+	require.Equal(t, int64(40), fee.Total)
+
+	// 1.5% of 33 is 0.495, rounded half up
+	fee, err = CalculateFee(schedule, 33)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), fee.PercentageFee)
+
+	fee, err = CalculateFee(schedule, 34)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), fee.PercentageFee)
+
+	_, err = CalculateFee(schedule, math.MaxInt64/100)
+	require.ErrorIs(t, err, money.ErrOverflow)
+}
+
+func TestGetFeeScheduleTiers(t *testing.T) {
+	currency := randomUnusedCurrency()
+	revenue := createCurrencyAccount(t, currency, 0)
+
+	low := createRandomFeeSchedule(t, CreateFeeScheduleParams{
+		Currency: currency,
+		FlatFee: 10,
+		RevenueAccountID: revenue.ID,
+	})
+	high := createRandomFeeSchedule(t, CreateFeeScheduleParams{
+		Currency: currency,
+		MinAmount: 1000,
+		PercentageBps: 50,
+		RevenueAccountID: revenue.ID,
+	})
+
+	fee, err := QuoteFee(context.Background(), testQueries, currency, 999)
+	require.NoError(t, err)
+	require.Equal(t, low.ID, fee.ScheduleID)
+	require.Equal(t, int64(10), fee.Total)
+
+	fee, err = QuoteFee(context.Background(), testQueries, currency, 2000)
+	require.NoError(t, err)
+	require.Equal(t, high.ID, fee.ScheduleID)
+	require.Equal(t, int64(10), fee.Total)
+
+	// no schedule for the currency means no fee
+	fee, err = QuoteFee(context.Background(), testQueries, randomUnusedCurrency(), 2000)
+	require.NoError(t, err)
+	require.Zero(t, fee.ScheduleID)
+	require.Zero(t, fee.Total)
+}
+
+func TestTransferTxFee(t *testing.T) {
+	store := NewStore(testDB, StoreOptions{})
+
+	currency := randomUnusedCurrency()
+	account1 := createCurrencyAccount(t, currency, 1000)
+	account2 := createCurrencyAccount(t, currency, 0)
+	revenue := createCurrencyAccount(t, currency, 0)
+
+	createRandomFeeSchedule(t, CreateFeeScheduleParams{
+		Currency: currency,
+		FlatFee: 5,
+		PercentageBps: 100,
+		RevenueAccountID: revenue.ID,
+	})
+
+	amount := int64(200)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID: account2.ID,
+		Amount: amount,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(5), result.Fee.FlatFee)
+	require.Equal(t, int64(2), result.Fee.PercentageFee)
+	require.Equal(t, int64(7), result.Fee.Total)
+	require.Equal(t, int64(7), result.Transfer.Fee)
+	require.Equal(t, money.Money{Amount: amount + 7, Currency: currency}, result.TotalDebit)
+
+	require.Equal(t, EntryTypeTransfer, result.FromEntry.Type)
+	require.Equal(t, EntryTypeFee, result.FeeEntry.Type)
+	require.Equal(t, account1.ID, result.FeeEntry.AccountID)
+	require.Equal(t, int64(-7), result.FeeEntry.Amount)
+	require.Equal(t, revenue.ID, result.RevenueEntry.AccountID)
+	require.Equal(t, int64(7), result.RevenueEntry.Amount)
+
+	require.Equal(t, int64(1000-200-7), result.FromAccount.Balance)
+	require.Equal(t, amount, result.ToAccount.Balance)
+	requireBalances(t, revenue.ID, 7, 7)
+
+	// fee entries do not count towards the daily transfer usage
+	usage, err := testQueries.GetDailyOutgoingUsage(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, amount, usage.TotalAmount)
+	require.Equal(t, int64(1), usage.TransferCount)
+}
@@ -0,0 +1,233 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	HoldAuthorized = "authorized"
+	HoldCaptured   = "captured"
+	HoldVoided     = "voided"
+	HoldExpired    = "expired"
+)
+
+var (
+	ErrInsufficientFunds  = errors.New("insufficient available balance")
+	ErrHoldNotAuthorized  = errors.New("hold is no longer authorized")
+	ErrHoldExpired        = errors.New("hold has expired")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the held amount")
+)
+
+// contains input parameters of the hold authorization
+type AuthorizeHoldTxParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	Amount int64 `json:"amount"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// contains results of the hold authorization
+type AuthorizeHoldTxResult struct {
+	Hold Hold `json:"hold"`
+	FromAccount Account `json:"from_account"`
+}
+
+// places a hold that reduces the available balance of the source account without touching its ledger balance
+func (store *txStore) AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (AuthorizeHoldTxResult, error) {
+	var result AuthorizeHoldTxResult
+
+	err := store.execTx(ctx, pgx.TxOptions{}, func(q Querier) error {
+		accounts, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+
+		if err != nil {
+			return err
+		}
+
+		if err := checkNotFrozen(accounts, arg.FromAccountID, arg.ToAccountID); err != nil {
+			return err
+		}
+
+		if accounts[arg.FromAccountID].AvailableBalance < arg.Amount {
+			return ErrInsufficientFunds
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID: arg.ToAccountID,
+			Amount: arg.Amount,
+			ExpiresAt: arg.ExpiresAt,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID: arg.FromAccountID,
+			Amount: -arg.Amount,
+		})
+
+		return err
+	})
+
+	return result, err
+}
+
+// contains input parameters of the hold capture
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// zero captures the full held amount
+	Amount int64 `json:"amount"`
+}
+
+// contains results of the hold capture
+type CaptureHoldTxResult struct {
+	Hold Hold `json:"hold"`
+	TransferTxResult
+}
+
+// releases the hold and transfers the captured amount; whatever is not captured goes back to the available balance
+func (store *txStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := store.execTx(ctx, pgx.TxOptions{}, func(q Querier) error {
+		hold, err := lockAuthorizedHold(ctx, q, arg.HoldID)
+
+		if err != nil {
+			return err
+		}
+
+		amount := arg.Amount
+
+		if amount == 0 {
+			amount = hold.Amount
+		}
+
+		if amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		arg := TransferTxParams{
+			FromAccountID: hold.FromAccountID,
+			ToAccountID: hold.ToAccountID,
+			Amount: amount,
+		}
+
+		// take the account locks before releasing, in the same order the transfer does
+		if _, _, err = lockTransfer(ctx, q, arg); err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID: hold.FromAccountID,
+			Amount: hold.Amount,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.TransferTxResult, err = transfer(ctx, q, arg)
+
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+			ID: hold.ID,
+			Status: HoldCaptured,
+			CapturedAmount: amount,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+
+		return err
+	})
+
+	return result, err
+}
+
+// releases the hold without moving any money
+func (store *txStore) VoidHoldTx(ctx context.Context, holdID int64) (Hold, error) {
+	var result Hold
+
+	err := store.execTx(ctx, pgx.TxOptions{}, func(q Querier) error {
+		hold, err := lockAuthorizedHold(ctx, q, holdID)
+
+		if err != nil && err != ErrHoldExpired {
+			return err
+		}
+
+		result, err = releaseHold(ctx, q, hold, HoldVoided)
+
+		return err
+	})
+
+	return result, err
+}
+
+// releases up to limit holds past their expiry and returns how many were released.
+// Accounts are locked from the highest ID down, the same order transfers use.
+func (store *txStore) ExpireHoldsTx(ctx context.Context, limit int32) (int, error) {
+	var released int
+
+	err := store.execTx(ctx, pgx.TxOptions{}, func(q Querier) error {
+		holds, err := q.ListExpiredHoldsForUpdate(ctx, limit)
+
+		if err != nil {
+			return err
+		}
+
+		for _, hold := range holds {
+			if _, err := releaseHold(ctx, q, hold, HoldExpired); err != nil {
+				return err
+			}
+		}
+
+		released = len(holds)
+
+		return nil
+	})
+
+	return released, err
+}
+
+// locks the hold and makes sure it can still be captured or voided
+func lockAuthorizedHold(ctx context.Context, q Querier, holdID int64) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+
+	if err != nil {
+		return hold, err
+	}
+
+	if hold.Status != HoldAuthorized {
+		return hold, ErrHoldNotAuthorized
+	}
+
+	if time.Now().After(hold.ExpiresAt) {
+		return hold, ErrHoldExpired
+	}
+
+	return hold, nil
+}
+
+// gives the held amount back to the available balance and closes the hold with the given status
+func releaseHold(ctx context.Context, q Querier, hold Hold, status string) (Hold, error) {
+	_, err := q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+		ID: hold.FromAccountID,
+		Amount: hold.Amount,
+	})
+
+	if err != nil {
+		return hold, err
+	}
+
+	return q.UpdateHold(ctx, UpdateHoldParams{
+		ID: hold.ID,
+		Status: status,
+	})
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// creates an account with enough money for the holds of the tests
+func createFundedAccount(t *testing.T) Account {
+	account := createRandomAccount(t)
+
+	account, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID: account.ID,
+		Amount: 1000,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
+func authorizeRandomHold(t *testing.T, store Store, from Account, to Account, amount int64, expiresAt time.Time) Hold {
+	result, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: from.ID,
+		ToAccountID: to.ID,
+		Amount: amount,
+		ExpiresAt: expiresAt,
+	})
+
+	require.NoError(t, err)
+	require.NotZero(t, result.Hold.ID)
+	require.Equal(t, HoldAuthorized, result.Hold.Status)
+	require.Equal(t, amount, result.Hold.Amount)
+
+	return result.Hold
+}
+
+func requireBalances(t *testing.T, accountID int64, balance int64, available int64) {
+	account, err := testQueries.GetAccount(context.Background(), accountID)
+	require.NoError(t, err)
+	require.Equal(t, balance, account.Balance)
+	require.Equal(t, available, account.AvailableBalance)
+}
+
+func TestAuthorizeHoldTx(t *testing.T) {
+	store := NewStore(testDB, StoreOptions{})
+
+	account1 := createFundedAccount(t)
+	account2 := createCurrencyAccount(t, account1.Currency, util.RandomMoney())
+
+	amount := account1.Balance / 2
+	authorizeRandomHold(t, store, account1, account2, amount, time.Now().Add(time.Hour))
+
+	// the hold reduces only the available balance
+	requireBalances(t, account1.ID, account1.Balance, account1.Balance-amount)
+
+	_, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID: account2.ID,
+		Amount: account1.Balance - amount + 1,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestCaptureHoldTx(t *testing.T) {
+	store := NewStore(testDB, StoreOptions{})
+
+	account1 := createFundedAccount(t)
+	account2 := createCurrencyAccount(t, account1.Currency, util.RandomMoney())
+
+	amount := int64(10)
+	hold := authorizeRandomHold(t, store, account1, account2, amount, time.Now().Add(time.Hour))
+
+	// partial capture releases the rest of the hold
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Amount: 4,
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldCaptured, result.Hold.Status)
+	require.Equal(t, int64(4), result.Hold.CapturedAmount)
+	require.Equal(t, result.Transfer.ID, result.Hold.TransferID.Int64)
+
+	requireBalances(t, account1.ID, account1.Balance-4, account1.Balance-4)
+	requireBalances(t, account2.ID, account2.Balance+4, account2.Balance+4)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+
+	hold = authorizeRandomHold(t, store, account1, account2, amount, time.Now().Add(time.Hour))
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Amount: amount + 1,
+	})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+}
+
+func TestVoidHoldTx(t *testing.T) {
+	store := NewStore(testDB, StoreOptions{})
+
+	account1 := createFundedAccount(t)
+	account2 := createCurrencyAccount(t, account1.Currency, util.RandomMoney())
+
+	hold := authorizeRandomHold(t, store, account1, account2, 10, time.Now().Add(time.Hour))
+
+	voided, err := store.VoidHoldTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldVoided, voided.Status)
+
+	requireBalances(t, account1.ID, account1.Balance, account1.Balance)
+
+	_, err = store.VoidHoldTx(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+}
+
+func TestExpireHoldsTx(t *testing.T) {
+	store := NewStore(testDB, StoreOptions{})
+
+	account1 := createFundedAccount(t)
+	account2 := createCurrencyAccount(t, account1.Currency, util.RandomMoney())
+
+	hold := authorizeRandomHold(t, store, account1, account2, 10, time.Now().Add(-time.Second))
+
+	_, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	released, err := store.ExpireHoldsTx(context.Background(), 1000)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, released, 1)
+
+	expired, err := testQueries.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldExpired, expired.Status)
+
+	requireBalances(t, account1.ID, account1.Balance, account1.Balance)
+}
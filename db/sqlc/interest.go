@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	ProductChecking = "checking"
+	ProductSavings  = "savings"
+
+	EntryTypeInterest = "interest"
+
+	// accruals are kept in millionths of the minor unit so daily rounding does not add up
+	MicrosPerMinorUnit = 1000000
+	daysPerYear        = 365
+)
+
+var ErrNoInterestExpenseAccount = errors.New("no interest expense account for the currency")
+
+// returns the interest of one day on the balance, actual/365, in millionths of the minor unit rounded half up.
+// Negative balances do not earn interest.
+func DailyInterestMicros(balance int64, annualRateBps int32) int64 {
+	if balance <= 0 || annualRateBps <= 0 {
+		return 0
+	}
+
+	// balance * rate / 10000 / 365 * 1000000, computed exactly before rounding
+	numerator := new(big.Int).Mul(big.NewInt(balance), big.NewInt(int64(annualRateBps)*MicrosPerMinorUnit/10000))
+	numerator.Mul(numerator, big.NewInt(2))
+	numerator.Add(numerator, big.NewInt(daysPerYear))
+
+	return numerator.Quo(numerator, big.NewInt(2*daysPerYear)).Int64()
+}
+
+// rounds accrued micros half up to the minor unit
+func MicrosToMinorUnits(micros int64) int64 {
+	return (micros + MicrosPerMinorUnit/2) / MicrosPerMinorUnit
+}
+
+// truncates t to midnight UTC, the calendar day accruals are recorded for
+func AccrualDate(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// records one day of interest for every interest bearing account, computed on its balance at the end of the day.
+// Days already accrued are skipped, so running it again for the same date is harmless.
+func (store *txStore) AccrueInterest(ctx context.Context, date time.Time) (int, error) {
+	date = AccrualDate(date)
+	endOfDay := date.AddDate(0, 0, 1)
+
+	accounts, err := store.ListInterestBearingAccounts(ctx, endOfDay)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var accrued int
+
+	for _, account := range accounts {
+		balance, err := store.GetBalanceAt(ctx, GetBalanceAtParams{
+			At: endOfDay,
+			AccountID: account.ID,
+		})
+
+		if err != nil {
+			return accrued, err
+		}
+
+		rows, err := store.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+			AccountID: account.ID,
+			AccrualDate: date,
+			Balance: balance,
+			AnnualRateBps: account.AnnualRateBps,
+			AmountMicros: DailyInterestMicros(balance, account.AnnualRateBps),
+		})
+
+		if err != nil {
+			return accrued, err
+		}
+
+		accrued += int(rows)
+	}
+
+	return accrued, nil
+}
+
+// posts the interest accrued before periodEnd to every account, moving it from the bank's
+// interest expense account of the currency. Each account is posted in its own transaction.
+func (store *txStore) PostInterest(ctx context.Context, periodEnd time.Time) (int, error) {
+	periodEnd = AccrualDate(periodEnd)
+
+	accountIDs, err := store.ListAccountsWithUnpostedInterest(ctx, periodEnd)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var posted int
+
+	for _, accountID := range accountIDs {
+		err := store.execTx(ctx, pgx.TxOptions{}, func(q Querier) error {
+			return postInterest(ctx, q, accountID, periodEnd)
+		})
+
+		if err != nil {
+			return posted, fmt.Errorf("cannot post interest of account [%d]: %w", accountID, err)
+		}
+
+		posted++
+	}
+
+	return posted, nil
+}
+
+func postInterest(ctx context.Context, q Querier, accountID int64, periodEnd time.Time) error {
+	account, err := q.GetAccount(ctx, accountID)
+
+	if err != nil {
+		return err
+	}
+
+	expenseAccountID, err := q.GetInterestExpenseAccount(ctx, account.Currency)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %s", ErrNoInterestExpenseAccount, account.Currency)
+		}
+		return err
+	}
+
+	_, err = lockAccounts(ctx, q, accountID, expenseAccountID)
+
+	if err != nil {
+		return err
+	}
+
+	accruals, err := q.ListUnpostedAccrualsForUpdate(ctx, ListUnpostedAccrualsForUpdateParams{
+		AccountID: accountID,
+		PeriodEnd: periodEnd,
+	})
+
+	if err != nil || len(accruals) == 0 {
+		return err
+	}
+
+	var micros int64
+
+	for _, accrual := range accruals {
+		micros += accrual.AmountMicros
+	}
+
+	amount := MicrosToMinorUnits(micros)
+
+	var entryID sql.NullInt64
+
+	if amount > 0 {
+		_, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: expenseAccountID,
+			Amount: -amount,
+			Type: EntryTypeInterest,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		entry, err := q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: accountID,
+			Amount: amount,
+			Type: EntryTypeInterest,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		entryID = sql.NullInt64{Int64: entry.ID, Valid: true}
+
+		if accountID > expenseAccountID {
+			_, _, err = addMoney(ctx, q, accountID, amount, expenseAccountID, -amount)
+		} else {
+			_, _, err = addMoney(ctx, q, expenseAccountID, -amount, accountID, amount)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	posting, err := q.CreateInterestPosting(ctx, CreateInterestPostingParams{
+		AccountID: accountID,
+		ExpenseAccountID: expenseAccountID,
+		PeriodEnd: periodEnd,
+		AccruedMicros: micros,
+		Amount: amount,
+		EntryID: entryID,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return q.MarkAccrualsPosted(ctx, MarkAccrualsPostedParams{
+		PostingID: sql.NullInt64{Int64: posting.ID, Valid: true},
+		AccountID: accountID,
+		PeriodEnd: periodEnd,
+	})
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyInterestMicros(t *testing.T) {
+	// 2% of 1000.00 over 365 days is 5.479452... cents a day
+	require.Equal(t, int64(5479452), DailyInterestMicros(100000, 200))
+	// 0.0000005 of a cent rounds up
+	require.Equal(t, int64(1), DailyInterestMicros(1, 2))
+	require.Zero(t, DailyInterestMicros(-100000, 200))
+	require.Zero(t, DailyInterestMicros(100000, 0))
+
+	require.Equal(t, int64(11), MicrosToMinorUnits(2*5479452))
+	require.Equal(t, int64(0), MicrosToMinorUnits(499999))
+	require.Equal(t, int64(1), MicrosToMinorUnits(500000))
+}
+
+func TestAccrueAndPostInterest(t *testing.T) {
+	store := NewStore(testDB, StoreOptions{})
+
+	currency := randomUnusedCurrency()
+	expense := createCurrencyAccount(t, currency, 0)
+
+	user := createRandomUser(t)
+	savings, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner: user.Username,
+		Balance: 100000,
+		Currency: currency,
+		Product: ProductSavings,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.SetInterestExpenseAccount(context.Background(), SetInterestExpenseAccountParams{
+		Currency: currency,
+		AccountID: expense.ID,
+	})
+	require.NoError(t, err)
+
+	today := AccrualDate(time.Now())
+
+	accrued, err := store.AccrueInterest(context.Background(), today)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, accrued, 1)
+
+	// a second run for the same day adds nothing
+	_, err = store.AccrueInterest(context.Background(), today)
+	require.NoError(t, err)
+
+	_, err = store.AccrueInterest(context.Background(), today.AddDate(0, 0, 1))
+	require.NoError(t, err)
+
+	accruals, err := testQueries.ListUnpostedAccrualsForUpdate(context.Background(), ListUnpostedAccrualsForUpdateParams{
+		AccountID: savings.ID,
+		PeriodEnd: today.AddDate(0, 0, 2),
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 2)
+
+	for _, accrual := range accruals {
+		require.Equal(t, int64(100000), accrual.Balance)
+		require.Equal(t, int32(200), accrual.AnnualRateBps)
+		require.Equal(t, int64(5479452), accrual.AmountMicros)
+	}
+
+	_, err = store.PostInterest(context.Background(), today.AddDate(0, 0, 2))
+	require.NoError(t, err)
+
+	requireBalances(t, savings.ID, 100011, 100011)
+	requireBalances(t, expense.ID, -11, -11)
+
+	report, err := testQueries.GetInterestAccrualReport(context.Background(), GetInterestAccrualReportParams{
+		FromDate: today,
+		ToDate: today.AddDate(0, 0, 2),
+	})
+	require.NoError(t, err)
+
+	var found bool
+
+	for _, row := range report {
+		if row.AccountID == savings.ID {
+			found = true
+			require.Equal(t, int64(2), row.Days)
+			require.Equal(t, int64(2*5479452), row.AccruedMicros)
+			require.Equal(t, row.AccruedMicros, row.PostedMicros)
+		}
+	}
+	require.True(t, found)
+
+	postings, err := testQueries.ListInterestPostings(context.Background(), ListInterestPostingsParams{
+		FromDate: today,
+		ToDate: today.AddDate(0, 0, 2),
+	})
+	require.NoError(t, err)
+
+	found = false
+
+	for _, posting := range postings {
+		if posting.AccountID == savings.ID {
+			found = true
+			require.Equal(t, expense.ID, posting.ExpenseAccountID)
+			require.Equal(t, int64(11), posting.Amount)
+			require.True(t, posting.EntryID.Valid)
+		}
+	}
+	require.True(t, found)
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+const (
+	LimitPerTransfer = "per_transfer"
+	LimitDailyAmount = "daily_amount"
+	LimitDailyCount  = "daily_count"
+)
+
+// returned by TransferTx when a transfer would breach a limit of the source account
+type TransferLimitError struct {
+	AccountID int64  `json:"account_id"`
+	Limit     string `json:"limit"`
+	Max       int64  `json:"max"`
+	Used      int64  `json:"used"`
+	Requested int64  `json:"requested"`
+}
+
+func (e *TransferLimitError) Error() string {
+	switch e.Limit {
+	case LimitPerTransfer:
+		return fmt.Sprintf("account [%d] transfer of %d exceeds the limit of %d per transfer", e.AccountID, e.Requested, e.Max)
+	case LimitDailyCount:
+		return fmt.Sprintf("account [%d] reached the limit of %d transfers per day", e.AccountID, e.Max)
+	}
+	return fmt.Sprintf("account [%d] transfer of %d exceeds the daily limit of %d, %d already used", e.AccountID, e.Requested, e.Max, e.Used)
+}
+
+// checks the transfer against the limits of the source account, or of its owner's tier when the account has none.
+// The caller must hold a lock on the source account so concurrent transfers cannot both pass the check.
+func checkTransferLimits(ctx context.Context, q Querier, accountID int64, amount int64) error {
+	limit, err := q.GetEffectiveTransferLimit(ctx, accountID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	if limit.MaxPerTransfer > 0 && amount > limit.MaxPerTransfer {
+		return &TransferLimitError{
+			AccountID: accountID,
+			Limit:     LimitPerTransfer,
+			Max:       limit.MaxPerTransfer,
+			Requested: amount,
+		}
+	}
+
+	if limit.MaxDailyAmount == 0 && limit.MaxDailyCount == 0 {
+		return nil
+	}
+
+	usage, err := q.GetDailyOutgoingUsage(ctx, accountID)
+
+	if err != nil {
+		return err
+	}
+
+	if limit.MaxDailyCount > 0 && usage.TransferCount >= int64(limit.MaxDailyCount) {
+		return &TransferLimitError{
+			AccountID: accountID,
+			Limit:     LimitDailyCount,
+			Max:       int64(limit.MaxDailyCount),
+			Used:      usage.TransferCount,
+			Requested: 1,
+		}
+	}
+
+	if limit.MaxDailyAmount > 0 && usage.TotalAmount+amount > limit.MaxDailyAmount {
+		return &TransferLimitError{
+			AccountID: accountID,
+			Limit:     LimitDailyAmount,
+			Max:       limit.MaxDailyAmount,
+			Used:      usage.TotalAmount,
+			Requested: amount,
+		}
+	}
+
+	return nil
+}
@@ -1,17 +1,17 @@
 package db
 
 import (
-	"database/sql"
+	"context"
 	"log"
 	"os"
 	"testing"
 
-	_ "github.com/lib/pq"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mateusribs/simple_bank/util"
 )
 
 var testQueries *Queries
-var testDB *sql.DB
+var testDB *pgxpool.Pool
 
 
 func TestMain(m *testing.M){
@@ -23,13 +23,14 @@ func TestMain(m *testing.M){
 		log.Fatal("cannot load config:", err)
 	}
 
-	testDB, err = sql.Open(config.DBDriver, config.DBSource)
+	testDB, err = pgxpool.New(context.Background(), config.DBSource)
 
 	if err != nil {
 		log.Fatal("cannot connect to database", err)
 	}
 
-	testQueries = New(testDB)
+	// through the wrapper the store uses, which reports missing rows as sql.ErrNoRows
+	testQueries = New(tracedDBTX{db: testDB})
 
 	os.Exit(m.Run())
-}
\ No newline at end of file
+}
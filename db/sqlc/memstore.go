@@ -0,0 +1,235 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// a Store kept in memory, for tests and local demos. It enforces the keys and foreign keys of the schema
+// and reports violations with the same Postgres errors, but runs one transaction at a time.
+type MemStore struct {
+	txStore
+	mu sync.Mutex
+	tables memTables
+	// sequences are not rolled back with the transaction, as in Postgres
+	lastIDs map[string]int64
+}
+
+// the rows of every table, keyed by primary key
+type memTables struct {
+	users map[string]User
+	accounts map[int64]Account
+	entries map[int64]Entry
+	transfers map[int64]Transfer
+	sessions map[uuid.UUID]Session
+	transferLimits map[int64]TransferLimit
+	transferChallenges map[uuid.UUID]TransferChallenge
+	holds map[int64]Hold
+	feeSchedules map[int64]FeeSchedule
+	products map[string]Product
+	currencies map[string]Currency
+	interestExpenseAccounts map[string]InterestExpenseAccount
+	interestPostings map[int64]InterestPosting
+	interestAccruals map[int64]InterestAccrual
+}
+
+// an empty store holding the rows the migrations seed, such as the products and currencies
+func NewMemStore() *MemStore {
+	store := &MemStore{
+		tables: memTables{
+			users: map[string]User{},
+			accounts: map[int64]Account{},
+			entries: map[int64]Entry{},
+			transfers: map[int64]Transfer{},
+			sessions: map[uuid.UUID]Session{},
+			transferLimits: map[int64]TransferLimit{},
+			transferChallenges: map[uuid.UUID]TransferChallenge{},
+			holds: map[int64]Hold{},
+			feeSchedules: map[int64]FeeSchedule{},
+			products: map[string]Product{},
+			currencies: map[string]Currency{},
+			interestExpenseAccounts: map[string]InterestExpenseAccount{},
+			interestPostings: map[int64]InterestPosting{},
+			interestAccruals: map[int64]InterestAccrual{},
+		},
+		lastIDs: map[string]int64{},
+	}
+
+	now := memNow()
+
+	store.tables.products[ProductChecking] = Product{Code: ProductChecking, Name: "Checking", CreatedAt: now}
+	store.tables.products[ProductSavings] = Product{Code: ProductSavings, Name: "Savings", AnnualRateBps: 200, CreatedAt: now}
+
+	for _, code := range []string{"USD", "EUR", "BRL"} {
+		store.tables.currencies[code] = Currency{Code: code, Exponent: 2, Enabled: true, UpdatedAt: now, CreatedAt: now}
+	}
+
+	store.txStore = txStore{
+		Querier: memQueries{store: store},
+		execTx: store.execTx,
+	}
+
+	return store
+}
+
+// holds the store for the whole transaction, which is stricter than any isolation level, and undoes
+// the writes of fn when it fails, so a rollback costs the rows written rather than the whole store
+func (store *MemStore) execTx(ctx context.Context, opts pgx.TxOptions, fn func(Querier) error) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tx := &memTx{}
+
+	if err := fn(memQueries{store: store, tx: tx}); err != nil {
+		tx.rollback()
+		return err
+	}
+
+	return nil
+}
+
+// the writes of a transaction, as funcs restoring each row to what it was before
+type memTx struct {
+	undo []func()
+}
+
+func (tx *memTx) rollback() {
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+}
+
+func (store *MemStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// always the version of the binary, since there is no schema to migrate
+func (store *MemStore) MigrationVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	return SchemaVersion, false, nil
+}
+
+// the next value of the bigserial column of table; the caller holds the lock
+func (store *MemStore) nextID(table string) int64 {
+	store.lastIDs[table]++
+	return store.lastIDs[table]
+}
+
+// the queries of a MemStore; outside a transaction each one holds the store while it runs
+type memQueries struct {
+	store *MemStore
+	// nil outside execTx
+	tx *memTx
+}
+
+// locks the store unless the queries run within execTx, which already holds it, and returns the unlock
+func (q memQueries) lock() func() {
+	if q.tx != nil {
+		return func() {}
+	}
+
+	q.store.mu.Lock()
+	return q.store.mu.Unlock
+}
+
+func (q memQueries) tables() *memTables {
+	return &q.store.tables
+}
+
+// writes the row of key; every write of the queries goes through memSet or memDelete, so a transaction can undo it
+func memSet[K comparable, V any](q memQueries, rows map[K]V, key K, value V) {
+	memRemember(q, rows, key)
+	rows[key] = value
+}
+
+func memDelete[K comparable, V any](q memQueries, rows map[K]V, key K) {
+	memRemember(q, rows, key)
+	delete(rows, key)
+}
+
+// within a transaction, records how to put the row of key back as it is now
+func memRemember[K comparable, V any](q memQueries, rows map[K]V, key K) {
+	if q.tx == nil {
+		return
+	}
+
+	old, existed := rows[key]
+
+	q.tx.undo = append(q.tx.undo, func() {
+		if existed {
+			rows[key] = old
+		} else {
+			delete(rows, key)
+		}
+	})
+}
+
+// timestamps with the microsecond precision of Postgres
+func memNow() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+// the calendar day of t, as Postgres stores a date parameter
+func memDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// applies LIMIT and OFFSET to rows already in order
+func memPage[T any](rows []T, limit int32, offset int32) []T {
+	if int(offset) >= len(rows) {
+		return []T{}
+	}
+
+	rows = rows[offset:]
+
+	if int(limit) < len(rows) {
+		rows = rows[:limit]
+	}
+
+	return rows
+}
+
+// the errors Postgres raises, so callers handle both stores the same way
+func uniqueViolation(table string, constraint string) error {
+	return &pgconn.PgError{
+		Severity: "ERROR",
+		Code: pgerrcode.UniqueViolation,
+		Message: fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		TableName: table,
+		ConstraintName: constraint,
+	}
+}
+
+func foreignKeyViolation(table string, constraint string) error {
+	return &pgconn.PgError{
+		Severity: "ERROR",
+		Code: pgerrcode.ForeignKeyViolation,
+		Message: fmt.Sprintf("insert or update on table %q violates foreign key constraint %q", table, constraint),
+		TableName: table,
+		ConstraintName: constraint,
+	}
+}
+
+// a delete or update of a row that rows of another table still reference
+func stillReferenced(table string, constraint string, referencing string) error {
+	return &pgconn.PgError{
+		Severity: "ERROR",
+		Code: pgerrcode.ForeignKeyViolation,
+		Message: fmt.Sprintf("update or delete on table %q violates foreign key constraint %q on table %q", table, constraint, referencing),
+		TableName: referencing,
+		ConstraintName: constraint,
+	}
+}
+
+var _ Store = (*MemStore)(nil)
+var _ Querier = memQueries{}
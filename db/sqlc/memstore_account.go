@@ -0,0 +1,293 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+)
+
+func (q memQueries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	if _, ok := t.users[arg.Owner]; !ok {
+		return Account{}, foreignKeyViolation("accounts", "accounts_owner_fkey")
+	}
+
+	if _, ok := t.products[arg.Product]; !ok {
+		return Account{}, foreignKeyViolation("accounts", "accounts_product_fkey")
+	}
+
+	for _, account := range t.accounts {
+		if account.Owner == arg.Owner && account.Currency == arg.Currency {
+			return Account{}, uniqueViolation("accounts", "owner_currency_key")
+		}
+	}
+
+	account := Account{
+		ID: q.store.nextID("accounts"),
+		Owner: arg.Owner,
+		Balance: arg.Balance,
+		Currency: arg.Currency,
+		CreatedAt: sql.NullTime{Time: memNow(), Valid: true},
+		AvailableBalance: arg.Balance,
+		Product: arg.Product,
+	}
+
+	memSet(q, t.accounts, account.ID, account)
+
+	return account, nil
+}
+
+func (q memQueries) GetAccount(ctx context.Context, id int64) (Account, error) {
+	defer q.lock()()
+
+	account, ok := q.tables().accounts[id]
+
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+
+	return account, nil
+}
+
+// transactions already run one at a time
+func (q memQueries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
+	return q.GetAccount(ctx, id)
+}
+
+func (q memQueries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
+	defer q.lock()()
+
+	accounts := []Account{}
+
+	for _, account := range q.tables().accounts {
+		if account.Owner == arg.Owner {
+			accounts = append(accounts, account)
+		}
+	}
+
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID < accounts[j].ID })
+
+	return memPage(accounts, arg.Limit, arg.Offset), nil
+}
+
+func (q memQueries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	account, ok := t.accounts[arg.ID]
+
+	if !ok || account.Owner != arg.Owner {
+		return Account{}, sql.ErrNoRows
+	}
+
+	account.AvailableBalance += arg.Balance - account.Balance
+	account.Balance = arg.Balance
+	memSet(q, t.accounts, account.ID, account)
+
+	return account, nil
+}
+
+func (q memQueries) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	account, ok := t.accounts[arg.ID]
+
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+
+	account.Balance += arg.Amount
+	account.AvailableBalance += arg.Amount
+	memSet(q, t.accounts, account.ID, account)
+
+	return account, nil
+}
+
+func (q memQueries) AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	account, ok := t.accounts[arg.ID]
+
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+
+	account.AvailableBalance += arg.Amount
+	memSet(q, t.accounts, account.ID, account)
+
+	return account, nil
+}
+
+func (q memQueries) DeleteAccount(ctx context.Context, id int64) error {
+	defer q.lock()()
+	t := q.tables()
+
+	if _, ok := t.accounts[id]; !ok {
+		return nil
+	}
+
+	for _, entry := range t.entries {
+		if entry.AccountID == id {
+			return stillReferenced("accounts", "entries_account_id_fkey", "entries")
+		}
+	}
+
+	for _, transfer := range t.transfers {
+		if transfer.FromAccountID == id || transfer.ToAccountID == id {
+			return stillReferenced("accounts", "transfers_from_account_id_fkey", "transfers")
+		}
+	}
+
+	for _, hold := range t.holds {
+		if hold.FromAccountID == id || hold.ToAccountID == id {
+			return stillReferenced("accounts", "holds_from_account_id_fkey", "holds")
+		}
+	}
+
+	for _, challenge := range t.transferChallenges {
+		if challenge.FromAccountID == id || challenge.ToAccountID == id {
+			return stillReferenced("accounts", "transfer_challenges_from_account_id_fkey", "transfer_challenges")
+		}
+	}
+
+	for _, limit := range t.transferLimits {
+		if limit.AccountID.Valid && limit.AccountID.Int64 == id {
+			return stillReferenced("accounts", "transfer_limits_account_id_fkey", "transfer_limits")
+		}
+	}
+
+	for _, schedule := range t.feeSchedules {
+		if schedule.RevenueAccountID == id {
+			return stillReferenced("accounts", "fee_schedules_revenue_account_id_fkey", "fee_schedules")
+		}
+	}
+
+	for _, expense := range t.interestExpenseAccounts {
+		if expense.AccountID == id {
+			return stillReferenced("accounts", "interest_expense_accounts_account_id_fkey", "interest_expense_accounts")
+		}
+	}
+
+	for _, posting := range t.interestPostings {
+		if posting.AccountID == id || posting.ExpenseAccountID == id {
+			return stillReferenced("accounts", "interest_postings_account_id_fkey", "interest_postings")
+		}
+	}
+
+	for _, accrual := range t.interestAccruals {
+		if accrual.AccountID == id {
+			return stillReferenced("accounts", "interest_accruals_account_id_fkey", "interest_accruals")
+		}
+	}
+
+	memDelete(q, t.accounts, id)
+
+	return nil
+}
+
+func (q memQueries) SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (Account, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	account, ok := t.accounts[arg.ID]
+
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+
+	account.IsFrozen = arg.IsFrozen
+	memSet(q, t.accounts, account.ID, account)
+
+	return account, nil
+}
+
+func (q memQueries) ReconcileAccounts(ctx context.Context) ([]ReconcileAccountsRow, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	ledger := map[int64]int64{}
+	held := map[int64]int64{}
+
+	for _, entry := range t.entries {
+		ledger[entry.AccountID] += entry.Amount
+	}
+
+	for _, hold := range t.holds {
+		if hold.Status == HoldAuthorized {
+			held[hold.FromAccountID] += hold.Amount
+		}
+	}
+
+	rows := []ReconcileAccountsRow{}
+
+	for _, account := range t.accounts {
+		expected := account.Balance - held[account.ID]
+
+		if account.Balance == ledger[account.ID] && account.AvailableBalance == expected {
+			continue
+		}
+
+		rows = append(rows, ReconcileAccountsRow{
+			ID: account.ID,
+			Owner: account.Owner,
+			Currency: account.Currency,
+			Balance: account.Balance,
+			LedgerBalance: ledger[account.ID],
+			AvailableBalance: account.AvailableBalance,
+			ExpectedAvailableBalance: expected,
+		})
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+
+	return rows, nil
+}
+
+func (q memQueries) GetCurrency(ctx context.Context, code string) (Currency, error) {
+	defer q.lock()()
+
+	currency, ok := q.tables().currencies[code]
+
+	if !ok {
+		return Currency{}, sql.ErrNoRows
+	}
+
+	return currency, nil
+}
+
+func (q memQueries) ListCurrencies(ctx context.Context) ([]Currency, error) {
+	defer q.lock()()
+
+	currencies := []Currency{}
+
+	for _, currency := range q.tables().currencies {
+		currencies = append(currencies, currency)
+	}
+
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i].Code < currencies[j].Code })
+
+	return currencies, nil
+}
+
+func (q memQueries) UpsertCurrency(ctx context.Context, arg UpsertCurrencyParams) (Currency, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	now := memNow()
+	currency, ok := t.currencies[arg.Code]
+
+	if !ok {
+		currency = Currency{Code: arg.Code, CreatedAt: now}
+	}
+
+	currency.Exponent = arg.Exponent
+	currency.Enabled = arg.Enabled
+	currency.UpdatedAt = now
+	memSet(q, t.currencies, arg.Code, currency)
+
+	return currency, nil
+}
@@ -0,0 +1,301 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+func (q memQueries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	if _, ok := t.accounts[arg.AccountID]; !ok {
+		return Entry{}, foreignKeyViolation("entries", "entries_account_id_fkey")
+	}
+
+	entry := Entry{
+		ID: q.store.nextID("entries"),
+		AccountID: arg.AccountID,
+		Amount: arg.Amount,
+		CreatedAt: memNow(),
+		Type: arg.Type,
+	}
+
+	memSet(q, t.entries, entry.ID, entry)
+
+	return entry, nil
+}
+
+func (q memQueries) GetEntry(ctx context.Context, id int64) (Entry, error) {
+	defer q.lock()()
+
+	entry, ok := q.tables().entries[id]
+
+	if !ok {
+		return Entry{}, sql.ErrNoRows
+	}
+
+	return entry, nil
+}
+
+func (q memQueries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
+	defer q.lock()()
+
+	entries := sortedEntries(q.tables())
+
+	return memPage(entries, arg.Limit, arg.Offset), nil
+}
+
+func (q memQueries) UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	entry, ok := t.entries[arg.ID]
+
+	if !ok {
+		return Entry{}, sql.ErrNoRows
+	}
+
+	entry.Amount = arg.Amount
+	memSet(q, t.entries, entry.ID, entry)
+
+	return entry, nil
+}
+
+func (q memQueries) DeleteEntry(ctx context.Context, id int64) error {
+	defer q.lock()()
+	t := q.tables()
+
+	for _, posting := range t.interestPostings {
+		if posting.EntryID.Valid && posting.EntryID.Int64 == id {
+			return stillReferenced("entries", "interest_postings_entry_id_fkey", "interest_postings")
+		}
+	}
+
+	memDelete(q, t.entries, id)
+
+	return nil
+}
+
+func (q memQueries) GetDailyOutgoingUsage(ctx context.Context, accountID int64) (GetDailyOutgoingUsageRow, error) {
+	defer q.lock()()
+
+	var usage GetDailyOutgoingUsageRow
+	since := time.Now().Add(-24 * time.Hour)
+
+	for _, entry := range q.tables().entries {
+		if entry.AccountID == accountID && entry.Amount < 0 && entry.Type == EntryTypeTransfer && entry.CreatedAt.After(since) {
+			usage.TotalAmount -= entry.Amount
+			usage.TransferCount++
+		}
+	}
+
+	return usage, nil
+}
+
+func (q memQueries) ListLedgerEntries(ctx context.Context, arg ListLedgerEntriesParams) ([]ListLedgerEntriesRow, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	rows := []ListLedgerEntriesRow{}
+
+	for _, entry := range sortedEntries(t) {
+		if entry.ID <= arg.AfterID || entry.CreatedAt.Before(arg.FromTime) || !entry.CreatedAt.Before(arg.ToTime) {
+			continue
+		}
+
+		rows = append(rows, ListLedgerEntriesRow{
+			ID: entry.ID,
+			AccountID: entry.AccountID,
+			Currency: t.accounts[entry.AccountID].Currency,
+			Amount: entry.Amount,
+			Type: entry.Type,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+
+	return memPage(rows, arg.PageSize, 0), nil
+}
+
+func sortedEntries(t *memTables) []Entry {
+	entries := make([]Entry, 0, len(t.entries))
+
+	for _, entry := range t.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+
+	return entries
+}
+
+func (q memQueries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	if _, ok := t.accounts[arg.FromAccountID]; !ok {
+		return Transfer{}, foreignKeyViolation("transfers", "transfers_from_account_id_fkey")
+	}
+
+	if _, ok := t.accounts[arg.ToAccountID]; !ok {
+		return Transfer{}, foreignKeyViolation("transfers", "transfers_to_account_id_fkey")
+	}
+
+	transfer := Transfer{
+		ID: q.store.nextID("transfers"),
+		FromAccountID: arg.FromAccountID,
+		ToAccountID: arg.ToAccountID,
+		Amount: arg.Amount,
+		CreatedAt: memNow(),
+		Fee: arg.Fee,
+	}
+
+	memSet(q, t.transfers, transfer.ID, transfer)
+
+	return transfer, nil
+}
+
+func (q memQueries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
+	defer q.lock()()
+
+	transfer, ok := q.tables().transfers[id]
+
+	if !ok {
+		return Transfer{}, sql.ErrNoRows
+	}
+
+	return transfer, nil
+}
+
+func (q memQueries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+	defer q.lock()()
+
+	transfers := []Transfer{}
+
+	for _, transfer := range q.tables().transfers {
+		transfers = append(transfers, transfer)
+	}
+
+	sort.Slice(transfers, func(i, j int) bool { return transfers[i].ID < transfers[j].ID })
+
+	return memPage(transfers, arg.Limit, arg.Offset), nil
+}
+
+func (q memQueries) UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	transfer, ok := t.transfers[arg.ID]
+
+	if !ok {
+		return Transfer{}, sql.ErrNoRows
+	}
+
+	transfer.Amount = arg.Amount
+	memSet(q, t.transfers, transfer.ID, transfer)
+
+	return transfer, nil
+}
+
+func (q memQueries) DeleteTransfer(ctx context.Context, id int64) error {
+	defer q.lock()()
+	t := q.tables()
+
+	for _, hold := range t.holds {
+		if hold.TransferID.Valid && hold.TransferID.Int64 == id {
+			return stillReferenced("transfers", "holds_transfer_id_fkey", "holds")
+		}
+	}
+
+	for _, challenge := range t.transferChallenges {
+		if challenge.TransferID.Valid && challenge.TransferID.Int64 == id {
+			return stillReferenced("transfers", "transfer_challenges_transfer_id_fkey", "transfer_challenges")
+		}
+	}
+
+	memDelete(q, t.transfers, id)
+
+	return nil
+}
+
+func (q memQueries) CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	if _, ok := t.accounts[arg.RevenueAccountID]; !ok {
+		return FeeSchedule{}, foreignKeyViolation("fee_schedules", "fee_schedules_revenue_account_id_fkey")
+	}
+
+	for _, schedule := range t.feeSchedules {
+		if schedule.Currency == arg.Currency && schedule.MinAmount == arg.MinAmount {
+			return FeeSchedule{}, uniqueViolation("fee_schedules", "currency_min_amount_key")
+		}
+	}
+
+	schedule := FeeSchedule{
+		ID: q.store.nextID("fee_schedules"),
+		Currency: arg.Currency,
+		MinAmount: arg.MinAmount,
+		FlatFee: arg.FlatFee,
+		PercentageBps: arg.PercentageBps,
+		RevenueAccountID: arg.RevenueAccountID,
+		CreatedAt: memNow(),
+	}
+
+	memSet(q, t.feeSchedules, schedule.ID, schedule)
+
+	return schedule, nil
+}
+
+// the schedule of the highest tier the amount reaches
+func (q memQueries) GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error) {
+	defer q.lock()()
+
+	var found *FeeSchedule
+
+	for _, schedule := range q.tables().feeSchedules {
+		if schedule.Currency != arg.Currency || schedule.MinAmount > arg.Amount {
+			continue
+		}
+
+		if found == nil || schedule.MinAmount > found.MinAmount {
+			schedule := schedule
+			found = &schedule
+		}
+	}
+
+	if found == nil {
+		return FeeSchedule{}, sql.ErrNoRows
+	}
+
+	return *found, nil
+}
+
+func (q memQueries) ListFeeSchedules(ctx context.Context) ([]FeeSchedule, error) {
+	defer q.lock()()
+
+	schedules := []FeeSchedule{}
+
+	for _, schedule := range q.tables().feeSchedules {
+		schedules = append(schedules, schedule)
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].Currency != schedules[j].Currency {
+			return schedules[i].Currency < schedules[j].Currency
+		}
+		return schedules[i].MinAmount < schedules[j].MinAmount
+	})
+
+	return schedules, nil
+}
+
+func (q memQueries) DeleteFeeSchedule(ctx context.Context, id int64) error {
+	defer q.lock()()
+
+	memDelete(q, q.tables().feeSchedules, id)
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (q memQueries) CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	if _, ok := t.accounts[arg.FromAccountID]; !ok {
+		return Hold{}, foreignKeyViolation("holds", "holds_from_account_id_fkey")
+	}
+
+	if _, ok := t.accounts[arg.ToAccountID]; !ok {
+		return Hold{}, foreignKeyViolation("holds", "holds_to_account_id_fkey")
+	}
+
+	now := memNow()
+
+	hold := Hold{
+		ID: q.store.nextID("holds"),
+		FromAccountID: arg.FromAccountID,
+		ToAccountID: arg.ToAccountID,
+		Amount: arg.Amount,
+		Status: HoldAuthorized,
+		ExpiresAt: arg.ExpiresAt,
+		UpdatedAt: now,
+		CreatedAt: now,
+	}
+
+	memSet(q, t.holds, hold.ID, hold)
+
+	return hold, nil
+}
+
+func (q memQueries) GetHold(ctx context.Context, id int64) (Hold, error) {
+	defer q.lock()()
+
+	hold, ok := q.tables().holds[id]
+
+	if !ok {
+		return Hold{}, sql.ErrNoRows
+	}
+
+	return hold, nil
+}
+
+func (q memQueries) GetHoldForUpdate(ctx context.Context, id int64) (Hold, error) {
+	return q.GetHold(ctx, id)
+}
+
+func (q memQueries) ListExpiredHoldsForUpdate(ctx context.Context, limit int32) ([]Hold, error) {
+	defer q.lock()()
+
+	holds := []Hold{}
+	now := time.Now()
+
+	for _, hold := range q.tables().holds {
+		if hold.Status == HoldAuthorized && hold.ExpiresAt.Before(now) {
+			holds = append(holds, hold)
+		}
+	}
+
+	sort.Slice(holds, func(i, j int) bool {
+		if holds[i].FromAccountID != holds[j].FromAccountID {
+			return holds[i].FromAccountID > holds[j].FromAccountID
+		}
+		return holds[i].ID < holds[j].ID
+	})
+
+	return memPage(holds, limit, 0), nil
+}
+
+func (q memQueries) UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	hold, ok := t.holds[arg.ID]
+
+	if !ok {
+		return Hold{}, sql.ErrNoRows
+	}
+
+	if arg.TransferID.Valid {
+		if _, ok := t.transfers[arg.TransferID.Int64]; !ok {
+			return Hold{}, foreignKeyViolation("holds", "holds_transfer_id_fkey")
+		}
+	}
+
+	hold.Status = arg.Status
+	hold.CapturedAmount = arg.CapturedAmount
+	hold.TransferID = arg.TransferID
+	hold.UpdatedAt = memNow()
+	memSet(q, t.holds, hold.ID, hold)
+
+	return hold, nil
+}
+
+func (q memQueries) CreateTransferChallenge(ctx context.Context, arg CreateTransferChallengeParams) (TransferChallenge, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	if _, ok := t.transferChallenges[arg.ID]; ok {
+		return TransferChallenge{}, uniqueViolation("transfer_challenges", "transfer_challenges_pkey")
+	}
+
+	if _, ok := t.users[arg.Username]; !ok {
+		return TransferChallenge{}, foreignKeyViolation("transfer_challenges", "transfer_challenges_username_fkey")
+	}
+
+	if _, ok := t.accounts[arg.FromAccountID]; !ok {
+		return TransferChallenge{}, foreignKeyViolation("transfer_challenges", "transfer_challenges_from_account_id_fkey")
+	}
+
+	if _, ok := t.accounts[arg.ToAccountID]; !ok {
+		return TransferChallenge{}, foreignKeyViolation("transfer_challenges", "transfer_challenges_to_account_id_fkey")
+	}
+
+	challenge := TransferChallenge{
+		ID: arg.ID,
+		Username: arg.Username,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID: arg.ToAccountID,
+		Amount: arg.Amount,
+		Status: ChallengePending,
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: memNow(),
+	}
+
+	memSet(q, t.transferChallenges, challenge.ID, challenge)
+
+	return challenge, nil
+}
+
+func (q memQueries) GetTransferChallenge(ctx context.Context, id uuid.UUID) (TransferChallenge, error) {
+	defer q.lock()()
+
+	challenge, ok := q.tables().transferChallenges[id]
+
+	if !ok {
+		return TransferChallenge{}, sql.ErrNoRows
+	}
+
+	return challenge, nil
+}
+
+func (q memQueries) GetTransferChallengeForUpdate(ctx context.Context, id uuid.UUID) (TransferChallenge, error) {
+	return q.GetTransferChallenge(ctx, id)
+}
+
+func (q memQueries) CompleteTransferChallenge(ctx context.Context, arg CompleteTransferChallengeParams) (TransferChallenge, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	challenge, ok := t.transferChallenges[arg.ID]
+
+	if !ok {
+		return TransferChallenge{}, sql.ErrNoRows
+	}
+
+	if arg.TransferID.Valid {
+		if _, ok := t.transfers[arg.TransferID.Int64]; !ok {
+			return TransferChallenge{}, foreignKeyViolation("transfer_challenges", "transfer_challenges_transfer_id_fkey")
+		}
+	}
+
+	challenge.Status = ChallengeCompleted
+	challenge.TransferID = arg.TransferID
+	memSet(q, t.transferChallenges, challenge.ID, challenge)
+
+	return challenge, nil
+}
+
+func (q memQueries) UpsertAccountTransferLimit(ctx context.Context, arg UpsertAccountTransferLimitParams) (TransferLimit, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	if arg.AccountID.Valid {
+		if _, ok := t.accounts[arg.AccountID.Int64]; !ok {
+			return TransferLimit{}, foreignKeyViolation("transfer_limits", "transfer_limits_account_id_fkey")
+		}
+	}
+
+	limit := q.upsertTransferLimit(func(limit TransferLimit) bool {
+		return arg.AccountID.Valid && limit.AccountID == arg.AccountID
+	})
+
+	limit.AccountID = arg.AccountID
+	limit.MaxPerTransfer = arg.MaxPerTransfer
+	limit.MaxDailyAmount = arg.MaxDailyAmount
+	limit.MaxDailyCount = arg.MaxDailyCount
+	memSet(q, t.transferLimits, limit.ID, limit)
+
+	return limit, nil
+}
+
+func (q memQueries) UpsertTierTransferLimit(ctx context.Context, arg UpsertTierTransferLimitParams) (TransferLimit, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	limit := q.upsertTransferLimit(func(limit TransferLimit) bool {
+		return arg.Tier.Valid && limit.Tier == arg.Tier
+	})
+
+	limit.Tier = arg.Tier
+	limit.MaxPerTransfer = arg.MaxPerTransfer
+	limit.MaxDailyAmount = arg.MaxDailyAmount
+	limit.MaxDailyCount = arg.MaxDailyCount
+	memSet(q, t.transferLimits, limit.ID, limit)
+
+	return limit, nil
+}
+
+// the limit the conflict clause of an upsert selects, or a new one; NULL keys never conflict
+func (q memQueries) upsertTransferLimit(conflicts func(TransferLimit) bool) TransferLimit {
+	now := memNow()
+
+	for _, limit := range q.tables().transferLimits {
+		if conflicts(limit) {
+			limit.UpdatedAt = now
+			return limit
+		}
+	}
+
+	return TransferLimit{ID: q.store.nextID("transfer_limits"), UpdatedAt: now, CreatedAt: now}
+}
+
+// the limit of the account, falling back to the limit of the tier of its owner
+func (q memQueries) GetEffectiveTransferLimit(ctx context.Context, accountID int64) (TransferLimit, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	var tier *string
+
+	if account, ok := t.accounts[accountID]; ok {
+		if user, ok := t.users[account.Owner]; ok {
+			tier = &user.Tier
+		}
+	}
+
+	var found *TransferLimit
+
+	for _, limit := range t.transferLimits {
+		if limit.AccountID.Valid && limit.AccountID.Int64 == accountID {
+			limit := limit
+			found = &limit
+			break
+		}
+
+		if tier != nil && limit.Tier.Valid && limit.Tier.String == *tier {
+			limit := limit
+			found = &limit
+		}
+	}
+
+	if found == nil {
+		return TransferLimit{}, sql.ErrNoRows
+	}
+
+	return *found, nil
+}
@@ -0,0 +1,278 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+func (q memQueries) ListInterestBearingAccounts(ctx context.Context, before time.Time) ([]ListInterestBearingAccountsRow, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	rows := []ListInterestBearingAccountsRow{}
+
+	for _, account := range t.accounts {
+		product := t.products[account.Product]
+
+		if product.AnnualRateBps <= 0 || !account.CreatedAt.Valid || !account.CreatedAt.Time.Before(before) {
+			continue
+		}
+
+		rows = append(rows, ListInterestBearingAccountsRow{
+			ID: account.ID,
+			Currency: account.Currency,
+			AnnualRateBps: product.AnnualRateBps,
+		})
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+
+	return rows, nil
+}
+
+// the ledger balance with the entries from at onwards taken back out
+func (q memQueries) GetBalanceAt(ctx context.Context, arg GetBalanceAtParams) (int64, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	account, ok := t.accounts[arg.AccountID]
+
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+
+	balance := account.Balance
+
+	for _, entry := range t.entries {
+		if entry.AccountID == account.ID && !entry.CreatedAt.Before(arg.At) {
+			balance -= entry.Amount
+		}
+	}
+
+	return balance, nil
+}
+
+// reports 0 rows when the account already accrued for the day
+func (q memQueries) CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	if _, ok := t.accounts[arg.AccountID]; !ok {
+		return 0, foreignKeyViolation("interest_accruals", "interest_accruals_account_id_fkey")
+	}
+
+	date := memDate(arg.AccrualDate)
+
+	for _, accrual := range t.interestAccruals {
+		if accrual.AccountID == arg.AccountID && accrual.AccrualDate.Equal(date) {
+			return 0, nil
+		}
+	}
+
+	accrual := InterestAccrual{
+		ID: q.store.nextID("interest_accruals"),
+		AccountID: arg.AccountID,
+		AccrualDate: date,
+		Balance: arg.Balance,
+		AnnualRateBps: arg.AnnualRateBps,
+		AmountMicros: arg.AmountMicros,
+		CreatedAt: memNow(),
+	}
+
+	memSet(q, t.interestAccruals, accrual.ID, accrual)
+
+	return 1, nil
+}
+
+// the zero time, as the query's 0001-01-01, when nothing was accrued yet
+func (q memQueries) GetLastInterestAccrualDate(ctx context.Context) (time.Time, error) {
+	defer q.lock()()
+
+	var last time.Time
+
+	for _, accrual := range q.tables().interestAccruals {
+		if accrual.AccrualDate.After(last) {
+			last = accrual.AccrualDate
+		}
+	}
+
+	return last, nil
+}
+
+func (q memQueries) ListAccountsWithUnpostedInterest(ctx context.Context, periodEnd time.Time) ([]int64, error) {
+	defer q.lock()()
+
+	seen := map[int64]bool{}
+	accountIDs := []int64{}
+
+	for _, accrual := range q.tables().interestAccruals {
+		if accrual.PostingID.Valid || !accrual.AccrualDate.Before(memDate(periodEnd)) || seen[accrual.AccountID] {
+			continue
+		}
+
+		seen[accrual.AccountID] = true
+		accountIDs = append(accountIDs, accrual.AccountID)
+	}
+
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+
+	return accountIDs, nil
+}
+
+func (q memQueries) ListUnpostedAccrualsForUpdate(ctx context.Context, arg ListUnpostedAccrualsForUpdateParams) ([]InterestAccrual, error) {
+	defer q.lock()()
+
+	accruals := []InterestAccrual{}
+
+	for _, accrual := range q.tables().interestAccruals {
+		if accrual.AccountID == arg.AccountID && !accrual.PostingID.Valid && accrual.AccrualDate.Before(memDate(arg.PeriodEnd)) {
+			accruals = append(accruals, accrual)
+		}
+	}
+
+	sort.Slice(accruals, func(i, j int) bool { return accruals[i].AccrualDate.Before(accruals[j].AccrualDate) })
+
+	return accruals, nil
+}
+
+func (q memQueries) CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	if _, ok := t.accounts[arg.AccountID]; !ok {
+		return InterestPosting{}, foreignKeyViolation("interest_postings", "interest_postings_account_id_fkey")
+	}
+
+	if _, ok := t.accounts[arg.ExpenseAccountID]; !ok {
+		return InterestPosting{}, foreignKeyViolation("interest_postings", "interest_postings_expense_account_id_fkey")
+	}
+
+	if arg.EntryID.Valid {
+		if _, ok := t.entries[arg.EntryID.Int64]; !ok {
+			return InterestPosting{}, foreignKeyViolation("interest_postings", "interest_postings_entry_id_fkey")
+		}
+	}
+
+	posting := InterestPosting{
+		ID: q.store.nextID("interest_postings"),
+		AccountID: arg.AccountID,
+		ExpenseAccountID: arg.ExpenseAccountID,
+		PeriodEnd: memDate(arg.PeriodEnd),
+		AccruedMicros: arg.AccruedMicros,
+		Amount: arg.Amount,
+		EntryID: arg.EntryID,
+		CreatedAt: memNow(),
+	}
+
+	memSet(q, t.interestPostings, posting.ID, posting)
+
+	return posting, nil
+}
+
+func (q memQueries) MarkAccrualsPosted(ctx context.Context, arg MarkAccrualsPostedParams) error {
+	defer q.lock()()
+	t := q.tables()
+
+	if arg.PostingID.Valid {
+		if _, ok := t.interestPostings[arg.PostingID.Int64]; !ok {
+			return foreignKeyViolation("interest_accruals", "interest_accruals_posting_id_fkey")
+		}
+	}
+
+	for id, accrual := range t.interestAccruals {
+		if accrual.AccountID == arg.AccountID && !accrual.PostingID.Valid && accrual.AccrualDate.Before(memDate(arg.PeriodEnd)) {
+			accrual.PostingID = arg.PostingID
+			memSet(q, t.interestAccruals, id, accrual)
+		}
+	}
+
+	return nil
+}
+
+func (q memQueries) GetInterestExpenseAccount(ctx context.Context, currency string) (int64, error) {
+	defer q.lock()()
+
+	expense, ok := q.tables().interestExpenseAccounts[currency]
+
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+
+	return expense.AccountID, nil
+}
+
+func (q memQueries) SetInterestExpenseAccount(ctx context.Context, arg SetInterestExpenseAccountParams) (InterestExpenseAccount, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	if _, ok := t.accounts[arg.AccountID]; !ok {
+		return InterestExpenseAccount{}, foreignKeyViolation("interest_expense_accounts", "interest_expense_accounts_account_id_fkey")
+	}
+
+	expense := InterestExpenseAccount{Currency: arg.Currency, AccountID: arg.AccountID}
+	memSet(q, t.interestExpenseAccounts, arg.Currency, expense)
+
+	return expense, nil
+}
+
+func (q memQueries) GetInterestAccrualReport(ctx context.Context, arg GetInterestAccrualReportParams) ([]GetInterestAccrualReportRow, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	from := memDate(arg.FromDate)
+	to := memDate(arg.ToDate)
+	reports := map[int64]*GetInterestAccrualReportRow{}
+
+	for _, accrual := range t.interestAccruals {
+		if accrual.AccrualDate.Before(from) || !accrual.AccrualDate.Before(to) {
+			continue
+		}
+
+		report, ok := reports[accrual.AccountID]
+
+		if !ok {
+			report = &GetInterestAccrualReportRow{
+				AccountID: accrual.AccountID,
+				Currency: t.accounts[accrual.AccountID].Currency,
+			}
+			reports[accrual.AccountID] = report
+		}
+
+		report.Days++
+		report.AccruedMicros += accrual.AmountMicros
+
+		if accrual.PostingID.Valid {
+			report.PostedMicros += accrual.AmountMicros
+		}
+	}
+
+	rows := make([]GetInterestAccrualReportRow, 0, len(reports))
+
+	for _, report := range reports {
+		rows = append(rows, *report)
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].AccountID < rows[j].AccountID })
+
+	return rows, nil
+}
+
+func (q memQueries) ListInterestPostings(ctx context.Context, arg ListInterestPostingsParams) ([]InterestPosting, error) {
+	defer q.lock()()
+
+	from := memDate(arg.FromDate)
+	to := memDate(arg.ToDate)
+	postings := []InterestPosting{}
+
+	for _, posting := range q.tables().interestPostings {
+		if posting.PeriodEnd.After(from) && !posting.PeriodEnd.After(to) {
+			postings = append(postings, posting)
+		}
+	}
+
+	sort.Slice(postings, func(i, j int) bool { return postings[i].ID < postings[j].ID })
+
+	return postings, nil
+}
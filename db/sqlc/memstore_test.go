@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemStoreRollsBack(t *testing.T) {
+	store := NewMemStore()
+	user := createStoreUser(t, store)
+	errFailed := errors.New("failed")
+
+	var account Account
+
+	err := store.execTx(context.Background(), pgx.TxOptions{}, func(q Querier) error {
+		var err error
+
+		account, err = q.CreateAccount(context.Background(), CreateAccountParams{
+			Owner: user.Username,
+			Balance: util.RandomMoney(),
+			Currency: util.RandomCurrency(),
+			Product: ProductChecking,
+		})
+		require.NoError(t, err)
+
+		return errFailed
+	})
+	require.ErrorIs(t, err, errFailed)
+
+	_, err = store.GetAccount(context.Background(), account.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestMemStoreRollsBackUpdates(t *testing.T) {
+	store := NewMemStore()
+	user := createStoreUser(t, store)
+	errFailed := errors.New("failed")
+
+	account, err := store.CreateAccount(context.Background(), CreateAccountParams{
+		Owner: user.Username,
+		Balance: 100,
+		Currency: util.RandomCurrency(),
+		Product: ProductChecking,
+	})
+	require.NoError(t, err)
+
+	// the row is written twice and then deleted, and must come back as it was before the first write
+	err = store.execTx(context.Background(), pgx.TxOptions{}, func(q Querier) error {
+		for i := 0; i < 2; i++ {
+			_, err := q.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account.ID, Amount: 10})
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, q.DeleteAccount(context.Background(), account.ID))
+
+		return errFailed
+	})
+	require.ErrorIs(t, err, errFailed)
+
+	got, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account, got)
+}
@@ -0,0 +1,206 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mateusribs/simple_bank/util"
+)
+
+func (q memQueries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	if _, ok := t.users[arg.Username]; ok {
+		return User{}, uniqueViolation("users", "users_pkey")
+	}
+
+	for _, user := range t.users {
+		if user.Email == arg.Email {
+			return User{}, uniqueViolation("users", "users_email_key")
+		}
+	}
+
+	user := User{
+		Username: arg.Username,
+		HashedPassword: arg.HashedPassword,
+		FullName: arg.FullName,
+		Email: arg.Email,
+		// the column default, 0001-01-01 00:00:00Z
+		PasswordChangedAt: time.Time{},
+		CreatedAt: memNow(),
+		Role: util.CustomerRole,
+		Tier: "standard",
+	}
+
+	memSet(q, t.users, user.Username, user)
+
+	return user, nil
+}
+
+func (q memQueries) GetUser(ctx context.Context, username string) (User, error) {
+	defer q.lock()()
+
+	user, ok := q.tables().users[username]
+
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+
+	return user, nil
+}
+
+// applies change to the user and returns it, as the UPDATE ... RETURNING queries do
+func (q memQueries) updateUser(username string, change func(*User)) (User, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	user, ok := t.users[username]
+
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+
+	change(&user)
+	memSet(q, t.users, username, user)
+
+	return user, nil
+}
+
+func (q memQueries) UpdateUserHashedPassword(ctx context.Context, arg UpdateUserHashedPasswordParams) (User, error) {
+	return q.updateUser(arg.Username, func(user *User) { user.HashedPassword = arg.HashedPassword })
+}
+
+func (q memQueries) ResetUserPassword(ctx context.Context, arg ResetUserPasswordParams) (User, error) {
+	return q.updateUser(arg.Username, func(user *User) {
+		user.HashedPassword = arg.HashedPassword
+		user.PasswordChangedAt = memNow()
+	})
+}
+
+func (q memQueries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	return q.updateUser(arg.Username, func(user *User) { user.Role = arg.Role })
+}
+
+func (q memQueries) UpdateUserTier(ctx context.Context, arg UpdateUserTierParams) (User, error) {
+	return q.updateUser(arg.Username, func(user *User) { user.Tier = arg.Tier })
+}
+
+func (q memQueries) UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) (User, error) {
+	return q.updateUser(arg.Username, func(user *User) { user.TotpSecret = arg.TotpSecret })
+}
+
+func (q memQueries) UpdateUserTOTPPendingSecret(ctx context.Context, arg UpdateUserTOTPPendingSecretParams) (User, error) {
+	return q.updateUser(arg.Username, func(user *User) { user.TotpPendingSecret = arg.TotpPendingSecret })
+}
+
+// like the WHERE of the query, reports no rows unless the pending secret is still the one given
+func (q memQueries) ConfirmUserTOTPSecret(ctx context.Context, arg ConfirmUserTOTPSecretParams) (User, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	user, ok := t.users[arg.Username]
+
+	if !ok || user.TotpPendingSecret != arg.TotpPendingSecret {
+		return User{}, sql.ErrNoRows
+	}
+
+	user.TotpSecret = user.TotpPendingSecret
+	user.TotpPendingSecret = ""
+	user.TotpLastCounter = arg.TotpLastCounter
+	memSet(q, t.users, arg.Username, user)
+
+	return user, nil
+}
+
+// reports no rows when the counter is not newer than the last one used
+func (q memQueries) UseUserTOTPCounter(ctx context.Context, arg UseUserTOTPCounterParams) (User, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	user, ok := t.users[arg.Username]
+
+	if !ok || user.TotpLastCounter >= arg.TotpLastCounter {
+		return User{}, sql.ErrNoRows
+	}
+
+	user.TotpLastCounter = arg.TotpLastCounter
+	memSet(q, t.users, arg.Username, user)
+
+	return user, nil
+}
+
+func (q memQueries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	if _, ok := t.sessions[arg.ID]; ok {
+		return Session{}, uniqueViolation("sessions", "sessions_pkey")
+	}
+
+	if _, ok := t.users[arg.Username]; !ok {
+		return Session{}, foreignKeyViolation("sessions", "sessions_username_fkey")
+	}
+
+	session := Session{
+		ID: arg.ID,
+		Username: arg.Username,
+		RefreshToken: arg.RefreshToken,
+		UserAgent: arg.UserAgent,
+		ClientIp: arg.ClientIp,
+		IsBlocked: arg.IsBlocked,
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: memNow(),
+	}
+
+	memSet(q, t.sessions, session.ID, session)
+
+	return session, nil
+}
+
+func (q memQueries) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	defer q.lock()()
+
+	session, ok := q.tables().sessions[id]
+
+	if !ok {
+		return Session{}, sql.ErrNoRows
+	}
+
+	return session, nil
+}
+
+func (q memQueries) BlockSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	session, ok := t.sessions[id]
+
+	if !ok {
+		return Session{}, sql.ErrNoRows
+	}
+
+	session.IsBlocked = true
+	memSet(q, t.sessions, id, session)
+
+	return session, nil
+}
+
+func (q memQueries) BlockUserSessions(ctx context.Context, username string) (int64, error) {
+	defer q.lock()()
+	t := q.tables()
+
+	var blocked int64
+
+	for id, session := range t.sessions {
+		if session.Username == username && !session.IsBlocked {
+			session.IsBlocked = true
+			memSet(q, t.sessions, id, session)
+			blocked++
+		}
+	}
+
+	return blocked, nil
+}
@@ -12,11 +12,28 @@ import (
 )
 
 type Account struct {
-	ID        int64        `json:"id"`
-	Owner     string       `json:"owner"`
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	// ledger balance
 	Balance   int64        `json:"balance"`
 	Currency  string       `json:"currency"`
 	CreatedAt sql.NullTime `json:"created_at"`
+	// ledger balance minus active holds
+	AvailableBalance int64  `json:"available_balance"`
+	Product          string `json:"product"`
+	// frozen accounts cannot send or receive transfers or place holds
+	IsFrozen bool `json:"is_frozen"`
+}
+
+type Currency struct {
+	// ISO 4217 code
+	Code string `json:"code"`
+	// digits of the minor unit
+	Exponent int32 `json:"exponent"`
+	// disabled currencies cannot be used for new accounts or transfers
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -25,6 +42,74 @@ type Entry struct {
 	// can be negativa or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// transfer or fee
+	Type string `json:"type"`
+}
+
+type FeeSchedule struct {
+	ID       int64  `json:"id"`
+	Currency string `json:"currency"`
+	// applies from this transfer amount up to the next tier
+	MinAmount int64 `json:"min_amount"`
+	FlatFee   int64 `json:"flat_fee"`
+	// basis points of the amount, 100 is 1%
+	PercentageBps    int32     `json:"percentage_bps"`
+	RevenueAccountID int64     `json:"revenue_account_id"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type Hold struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// must be positive
+	Amount         int64 `json:"amount"`
+	CapturedAmount int64 `json:"captured_amount"`
+	// authorized, captured, voided or expired
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	// ledger balance at the end of the accrual date
+	Balance       int64 `json:"balance"`
+	AnnualRateBps int32 `json:"annual_rate_bps"`
+	// interest in millionths of the minor unit
+	AmountMicros int64         `json:"amount_micros"`
+	PostingID    sql.NullInt64 `json:"posting_id"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type InterestExpenseAccount struct {
+	Currency  string `json:"currency"`
+	AccountID int64  `json:"account_id"`
+}
+
+type InterestPosting struct {
+	ID               int64 `json:"id"`
+	AccountID        int64 `json:"account_id"`
+	ExpenseAccountID int64 `json:"expense_account_id"`
+	// accruals before this date are included
+	PeriodEnd     time.Time `json:"period_end"`
+	AccruedMicros int64     `json:"accrued_micros"`
+	// accrued_micros rounded half up to the minor unit
+	Amount    int64         `json:"amount"`
+	EntryID   sql.NullInt64 `json:"entry_id"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type Product struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// nominal annual interest rate in basis points, 100 is 1%
+	AnnualRateBps int32     `json:"annual_rate_bps"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type Session struct {
@@ -45,6 +130,35 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// charged to the sender on top of the amount
+	Fee int64 `json:"fee"`
+}
+
+type TransferChallenge struct {
+	ID            uuid.UUID `json:"id"`
+	Username      string    `json:"username"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	// pending or completed
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type TransferLimit struct {
+	ID        int64          `json:"id"`
+	AccountID sql.NullInt64  `json:"account_id"`
+	Tier      sql.NullString `json:"tier"`
+	// 0 means unlimited
+	MaxPerTransfer int64 `json:"max_per_transfer"`
+	// rolling 24 hours, 0 means unlimited
+	MaxDailyAmount int64 `json:"max_daily_amount"`
+	// rolling 24 hours, 0 means unlimited
+	MaxDailyCount int32     `json:"max_daily_count"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type User struct {
@@ -54,4 +168,12 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Role              string    `json:"role"`
+	Tier              string    `json:"tier"`
+	// encrypted with TOTP_ENCRYPTION_KEY
+	TotpSecret string `json:"totp_secret"`
+	// an enrolled secret not yet confirmed with a code, encrypted like totp_secret
+	TotpPendingSecret string `json:"totp_pending_secret"`
+	// time step of the last accepted TOTP code, codes of this step or earlier are replays
+	TotpLastCounter int64 `json:"totp_last_counter"`
 }
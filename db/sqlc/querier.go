@@ -6,32 +6,79 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	BlockSession(ctx context.Context, id uuid.UUID) (Session, error)
+	BlockUserSessions(ctx context.Context, username string) (int64, error)
+	CompleteTransferChallenge(ctx context.Context, arg CompleteTransferChallengeParams) (TransferChallenge, error)
+	ConfirmUserTOTPSecret(ctx context.Context, arg ConfirmUserTOTPSecretParams) (User, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error)
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferChallenge(ctx context.Context, arg CreateTransferChallengeParams) (TransferChallenge, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteAccount(ctx context.Context, id int64) error
 	DeleteEntry(ctx context.Context, id int64) error
+	DeleteFeeSchedule(ctx context.Context, id int64) error
 	DeleteTransfer(ctx context.Context, id int64) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetBalanceAt(ctx context.Context, arg GetBalanceAtParams) (int64, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
+	GetDailyOutgoingUsage(ctx context.Context, accountID int64) (GetDailyOutgoingUsageRow, error)
+	GetEffectiveTransferLimit(ctx context.Context, accountID int64) (TransferLimit, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetInterestAccrualReport(ctx context.Context, arg GetInterestAccrualReportParams) ([]GetInterestAccrualReportRow, error)
+	GetInterestExpenseAccount(ctx context.Context, currency string) (int64, error)
+	GetLastInterestAccrualDate(ctx context.Context) (time.Time, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferChallenge(ctx context.Context, id uuid.UUID) (TransferChallenge, error)
+	GetTransferChallengeForUpdate(ctx context.Context, id uuid.UUID) (TransferChallenge, error)
 	GetUser(ctx context.Context, username string) (User, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsWithUnpostedInterest(ctx context.Context, periodEnd time.Time) ([]int64, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListExpiredHoldsForUpdate(ctx context.Context, limit int32) ([]Hold, error)
+	ListFeeSchedules(ctx context.Context) ([]FeeSchedule, error)
+	ListInterestBearingAccounts(ctx context.Context, before time.Time) ([]ListInterestBearingAccountsRow, error)
+	ListInterestPostings(ctx context.Context, arg ListInterestPostingsParams) ([]InterestPosting, error)
+	ListLedgerEntries(ctx context.Context, arg ListLedgerEntriesParams) ([]ListLedgerEntriesRow, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUnpostedAccrualsForUpdate(ctx context.Context, arg ListUnpostedAccrualsForUpdateParams) ([]InterestAccrual, error)
+	MarkAccrualsPosted(ctx context.Context, arg MarkAccrualsPostedParams) error
+	ReconcileAccounts(ctx context.Context) ([]ReconcileAccountsRow, error)
+	ResetUserPassword(ctx context.Context, arg ResetUserPasswordParams) (User, error)
+	SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (Account, error)
+	SetInterestExpenseAccount(ctx context.Context, arg SetInterestExpenseAccountParams) (InterestExpenseAccount, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
 	UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error)
+	UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error)
 	UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error)
+	UpdateUserHashedPassword(ctx context.Context, arg UpdateUserHashedPasswordParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpdateUserTOTPPendingSecret(ctx context.Context, arg UpdateUserTOTPPendingSecretParams) (User, error)
+	UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) (User, error)
+	UpdateUserTier(ctx context.Context, arg UpdateUserTierParams) (User, error)
+	UpsertAccountTransferLimit(ctx context.Context, arg UpsertAccountTransferLimitParams) (TransferLimit, error)
+	UpsertCurrency(ctx context.Context, arg UpsertCurrencyParams) (Currency, error)
+	UpsertTierTransferLimit(ctx context.Context, arg UpsertTierTransferLimitParams) (TransferLimit, error)
+	UseUserTOTPCounter(ctx context.Context, arg UseUserTOTPCounterParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
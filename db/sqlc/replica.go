@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+)
+
+type readYourWritesKey struct{}
+
+// asks the store to serve the reads of ctx from the primary, for callers that must see writes
+// the replica may not have replayed yet, such as an account created a moment ago
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, true)
+}
+
+func readYourWrites(ctx context.Context) bool {
+	consistent, _ := ctx.Value(readYourWritesKey{}).(bool)
+	return consistent
+}
+
+// runs the account reads and the entry and transfer history on a replica, which lags behind the primary,
+// and every other query on the primary
+type replicaQuerier struct {
+	Querier
+	replica Querier
+}
+
+func (q replicaQuerier) reader(ctx context.Context) Querier {
+	if readYourWrites(ctx) {
+		return q.Querier
+	}
+
+	return q.replica
+}
+
+func (q replicaQuerier) GetAccount(ctx context.Context, id int64) (Account, error) {
+	return q.reader(ctx).GetAccount(ctx, id)
+}
+
+func (q replicaQuerier) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
+	return q.reader(ctx).ListAccounts(ctx, arg)
+}
+
+func (q replicaQuerier) GetEntry(ctx context.Context, id int64) (Entry, error) {
+	return q.reader(ctx).GetEntry(ctx, id)
+}
+
+func (q replicaQuerier) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
+	return q.reader(ctx).ListEntries(ctx, arg)
+}
+
+func (q replicaQuerier) ListLedgerEntries(ctx context.Context, arg ListLedgerEntriesParams) ([]ListLedgerEntriesRow, error) {
+	return q.reader(ctx).ListLedgerEntries(ctx, arg)
+}
+
+func (q replicaQuerier) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
+	return q.reader(ctx).GetTransfer(ctx, id)
+}
+
+func (q replicaQuerier) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+	return q.reader(ctx).ListTransfers(ctx, arg)
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicaQuerier(t *testing.T) {
+	primary := NewMemStore()
+	replica := NewMemStore()
+	q := replicaQuerier{Querier: primary, replica: replica}
+	ctx := context.Background()
+
+	// the replica has not replayed the writes yet
+	user, err := q.CreateUser(ctx, CreateUserParams{
+		Username: util.RandomOwner(),
+		HashedPassword: util.RandomString(32),
+		FullName: util.RandomOwner(),
+		Email: util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	account, err := q.CreateAccount(ctx, CreateAccountParams{
+		Owner: user.Username,
+		Balance: util.RandomMoney(),
+		Currency: util.RandomCurrency(),
+		Product: ProductChecking,
+	})
+	require.NoError(t, err)
+
+	_, err = q.GetAccount(ctx, account.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	accounts, err := q.ListAccounts(ctx, ListAccountsParams{Owner: user.Username, Limit: 5})
+	require.NoError(t, err)
+	require.Empty(t, accounts)
+
+	consistent, err := q.GetAccount(WithReadYourWrites(ctx), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account, consistent)
+
+	accounts, err = q.ListAccounts(WithReadYourWrites(ctx), ListAccountsParams{Owner: user.Username, Limit: 5})
+	require.NoError(t, err)
+	require.Equal(t, []Account{account}, accounts)
+}
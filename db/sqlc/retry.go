@@ -0,0 +1,45 @@
+package db
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// times execTx runs a transaction that keeps failing with a retryable error
+const maxTxAttempts = 5
+
+// the backoff before the first retry of a transaction, doubling up to maxTxRetryBackoff
+const (
+	minTxRetryBackoff = 10 * time.Millisecond
+	maxTxRetryBackoff = 500 * time.Millisecond
+)
+
+// span attribute with how many times execTx ran the transaction
+const txAttemptsKey = attribute.Key("db.tx.attempts")
+
+// errors Postgres raises when concurrent transactions conflict; running the transaction again usually succeeds
+var retryableCodes = map[string]bool{
+	pgerrcode.SerializationFailure: true,
+	pgerrcode.DeadlockDetected: true,
+}
+
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && retryableCodes[pgErr.Code]
+}
+
+// a random delay up to the exponential backoff of the attempt, so the conflicting transactions do not retry in lockstep
+func txRetryBackoff(attempt int) time.Duration {
+	backoff := minTxRetryBackoff << (attempt - 1)
+
+	if backoff <= 0 || backoff > maxTxRetryBackoff {
+		backoff = maxTxRetryBackoff
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff)))
+}
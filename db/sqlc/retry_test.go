@@ -0,0 +1,27 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryable(t *testing.T) {
+	require.True(t, isRetryable(&pgconn.PgError{Code: "40001"}))
+	require.True(t, isRetryable(fmt.Errorf("cannot lock account: %w", &pgconn.PgError{Code: "40P01"})))
+	require.False(t, isRetryable(&pgconn.PgError{Code: "23505"}))
+	require.False(t, isRetryable(ErrInsufficientFunds))
+}
+
+func TestTxRetryBackoff(t *testing.T) {
+	for attempt := 1; attempt < 100; attempt++ {
+		backoff := txRetryBackoff(attempt)
+		require.GreaterOrEqual(t, backoff, time.Duration(0))
+		require.Less(t, backoff, maxTxRetryBackoff)
+	}
+
+	require.Less(t, txRetryBackoff(1), minTxRetryBackoff)
+}
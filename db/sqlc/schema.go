@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mateusribs/simple_bank/db/migration"
+)
+
+// the migration the code is written against, the latest one embedded in the binary
+var SchemaVersion = migration.LatestVersion()
+
+// checks the database, and the replica when there is one, can be reached
+func (store *SQLStore) Ping(ctx context.Context) error {
+	if err := store.db.Ping(ctx); err != nil {
+		return err
+	}
+
+	if store.replica != nil {
+		if err := store.replica.Ping(ctx); err != nil {
+			return fmt.Errorf("replica: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// the version golang-migrate recorded and whether a migration failed halfway, leaving the schema dirty
+func (store *SQLStore) MigrationVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	err = store.db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	return
+}
@@ -3,48 +3,142 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mateusribs/simple_bank/logger"
+	"github.com/mateusribs/simple_bank/metrics"
+	"github.com/mateusribs/simple_bank/money"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// returned when a transfer or hold touches an account an operator froze
+var ErrAccountFrozen = errors.New("account is frozen")
+
 // provides all functions to execute db queries and transactions
 type Store interface {
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	CompleteTransferChallengeTx(ctx context.Context, challengeID uuid.UUID) (TransferTxResult, error)
+	AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (AuthorizeHoldTxResult, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	VoidHoldTx(ctx context.Context, holdID int64) (Hold, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error)
+	ExpireHoldsTx(ctx context.Context, limit int32) (int, error)
+	AccrueInterest(ctx context.Context, date time.Time) (int, error)
+	PostInterest(ctx context.Context, periodEnd time.Time) (int, error)
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version int64, dirty bool, err error)
 	Querier
 }
 
 // provides all functions to execute SQL queries and transactions
 type SQLStore struct {
-	*Queries
-	db *sql.DB
+	txStore
+	db *pgxpool.Pool
+	// nil without a replica
+	replica *pgxpool.Pool
+	queryTimeout time.Duration
+}
+
+// the transactions of a Store, written against Querier so every implementation shares them
+type txStore struct {
+	Querier
+	// runs fn within a single transaction of the backing store, with fn's queries bound to it
+	execTx func(ctx context.Context, opts pgx.TxOptions, fn func(Querier) error) error
+}
+
+// tunes a SQLStore; the zero value runs statements with the deadline of the caller only
+type StoreOptions struct {
+	// bounds each statement through its context deadline, DB_QUERY_TIMEOUT in the config
+	QueryTimeout time.Duration
+	// a read-only replica serving account reads and history unless the context asks for read-your-writes;
+	// nil sends every query to the primary. Writes and transactions always run on the primary.
+	Replica *pgxpool.Pool
 }
 
-func NewStore(db *sql.DB) Store {
-	return &SQLStore{
-		Queries: New(db),
+func NewStore(db *pgxpool.Pool, options StoreOptions) Store {
+	store := &SQLStore{
 		db: db,
+		replica: options.Replica,
+		queryTimeout: options.QueryTimeout,
+	}
+
+	var querier Querier = New(tracedDBTX{db: db, timeout: options.QueryTimeout})
+
+	if options.Replica != nil {
+		querier = replicaQuerier{
+			Querier: querier,
+			replica: New(tracedDBTX{db: options.Replica, timeout: options.QueryTimeout, replica: true}),
+		}
+	}
+
+	store.txStore = txStore{
+		Querier: querier,
+		execTx: store.execTx,
+	}
+
+	return store
+}
+
+// executes a function whithin a database transaction, at the isolation level of opts; the zero value uses the default one.
+// Serialization failures and deadlocks roll the transaction back and run fn again, so fn must only
+// have effects through q and must set its results from scratch on every call.
+func (store *SQLStore) execTx(ctx context.Context, opts pgx.TxOptions, fn func(Querier) error) (err error) {
+	ctx, span := startSpan(ctx, "execTx")
+	defer func() { endSpan(span, err) }()
+
+	for attempt := 1; ; attempt++ {
+		err = store.runTx(ctx, span, opts, fn)
+
+		if err == nil || !isRetryable(err) || attempt == maxTxAttempts {
+			span.SetAttributes(txAttemptsKey.Int(attempt))
+			return err
+		}
+
+		backoff := txRetryBackoff(attempt)
+		logger.FromContext(ctx).Debug().Err(err).Int("attempt", attempt).Dur("retry_in", backoff).Msg("retrying transaction")
+		metrics.TxRetries.Inc()
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			span.SetAttributes(txAttemptsKey.Int(attempt))
+			return err
+		case <-timer.C:
+		}
 	}
 }
 
-// executes a function whithin a database transaction
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+// a single attempt of execTx
+func (store *SQLStore) runTx(ctx context.Context, span trace.Span, opts pgx.TxOptions, fn func(Querier) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 
 	if err != nil {
 		return err
 	}
 
-	q := New(tx)
+	q := New(tracedDBTX{db: tx, txSpan: span, timeout: store.queryTimeout})
 
 	err = fn(q)
 
 	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
+		// the request logger of the context carries the request ID and user that caused the rollback
+		logger.FromContext(ctx).Debug().Err(err).Msg("transaction rolled back")
+
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
 			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
 		}
 		return err
 	}
 
-	return tx.Commit()
+	return tx.Commit(ctx)
 }
 
 // contains input parameters of transfer transaction
@@ -61,69 +155,194 @@ type TransferTxResult struct {
 	ToAccount Account `json:"to_account"`
 	FromEntry Entry `json:"from_entry"`
 	ToEntry Entry `json:"to_entry"`
+	Fee FeeBreakdown `json:"fee"`
+	FeeEntry Entry `json:"fee_entry"`
+	RevenueEntry Entry `json:"revenue_entry"`
+	TotalDebit money.Money `json:"total_debit"`
 }
 
 
 // performs money transfers from one account to the other
 // creates a transfer record, add accounts entries and update accounts balance whithin a single database transaction
-func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error){
+func (store *txStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error){
 	var result TransferTxResult
+	start := time.Now()
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	ctx, span := startSpan(ctx, "TransferTx", fromAccountIDKey.Int64(arg.FromAccountID), toAccountIDKey.Int64(arg.ToAccountID))
+
+	err := store.execTx(ctx, pgx.TxOptions{}, func(q Querier) error {
 		var err error
 
-		// create transfer
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID: arg.ToAccountID,
-			Amount: arg.Amount,
-		})
+		result, err = transfer(ctx, q, arg)
 
-		if err != nil {
-			return err
-		}
-		
-		//add accounts entries
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount: -arg.Amount,
-		})
+		return err
+	})
 
-		if err != nil {
-			return err
+	endSpan(span, err)
+	observeTransfer(start, result, err)
+
+	return result, err
+}
+
+// records the duration and outcome of a transfer transaction, and counts committed transfers by currency
+func observeTransfer(start time.Time, result TransferTxResult, err error) {
+	if err != nil {
+		metrics.TransferTxDuration.WithLabelValues("failure").Observe(time.Since(start).Seconds())
+		metrics.TransferTxFailures.WithLabelValues(transferFailureReason(err)).Inc()
+		return
+	}
+
+	metrics.TransferTxDuration.WithLabelValues("success").Observe(time.Since(start).Seconds())
+	metrics.Transfers.WithLabelValues(result.TotalDebit.Currency).Inc()
+	metrics.TransferredAmount.WithLabelValues(result.TotalDebit.Currency).Add(float64(result.Transfer.Amount))
+}
+
+func transferFailureReason(err error) string {
+	var limitErr *TransferLimitError
+
+	switch {
+	case errors.As(err, &limitErr):
+		return "limit_exceeded"
+	case errors.Is(err, money.ErrCurrencyMismatch):
+		return "currency_mismatch"
+	case errors.Is(err, money.ErrOverflow):
+		return "overflow"
+	case errors.Is(err, sql.ErrNoRows):
+		return "not_found"
+	case errors.Is(err, ErrChallengeCompleted), errors.Is(err, ErrChallengeExpired):
+		return "challenge_unusable"
+	case errors.Is(err, ErrAccountFrozen):
+		return "account_frozen"
+	case errors.Is(err, ErrInsufficientFunds):
+		return "insufficient_funds"
+	}
+
+	return "error"
+}
+
+// runs the statements of a transfer with the queries of an open transaction
+func transfer(ctx context.Context, q Querier, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	// lock the accounts in a consistent order, so the limit check below
+	// sees every committed transfer and concurrent transfers cannot deadlock
+	result.Fee, result.TotalDebit, err = lockTransfer(ctx, q, arg)
+
+	if err != nil {
+		return result, err
+	}
+
+	// read again under the lock, so money a hold reserved, or a capture just released, is counted
+	fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+
+	if err != nil {
+		return result, err
+	}
+
+	if fromAccount.AvailableBalance < result.TotalDebit.Amount {
+		return result, fmt.Errorf("%w: account [%d]", ErrInsufficientFunds, fromAccount.ID)
+	}
+
+	err = checkTransferLimits(ctx, q, arg.FromAccountID, arg.Amount)
+
+	if err != nil {
+		return result, err
+	}
+
+	// create transfer
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID: arg.ToAccountID,
+		Amount: arg.Amount,
+		Fee: result.Fee.Total,
+	})
+
+	if err != nil {
+		return result, err
+	}
+	
+	//add accounts entries
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: arg.FromAccountID,
+		Amount: -arg.Amount,
+		Type: EntryTypeTransfer,
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: arg.ToAccountID,
+		Amount: arg.Amount,
+		Type: EntryTypeTransfer,
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	// update balance
+	
+	if arg.FromAccountID > arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(
+			ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount,
+		)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(
+			ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount,
+		)
+	}
+
+	if err != nil || result.Fee.Total == 0 {
+		return result, err
+	}
+
+	err = chargeFee(ctx, q, arg.FromAccountID, result.Fee, &result)
+
+	return result, err
+}
+
+// locks the rows of the accounts, the higher ID first as in addMoney, and returns the locked rows by ID
+func lockAccounts(ctx context.Context, q Querier, accountIDs ...int64) (map[int64]Account, error) {
+	ids := append([]int64(nil), accountIDs...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+
+	accounts := make(map[int64]Account, len(ids))
+
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount: arg.Amount,
-		})
+		account, err := q.GetAccountForUpdate(ctx, id)
 
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// update balance
-		
-		if arg.FromAccountID > arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(
-				ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount,
-			)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(
-				ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount,
-			)
-		}
+		accounts[id] = account
+	}
 
+	return accounts, nil
+}
 
-		return nil
-	})
+// fails with ErrAccountFrozen when one of the accounts is frozen; the rows must come from lockAccounts,
+// so a freeze committed while the transaction waited for the locks is seen
+func checkNotFrozen(accounts map[int64]Account, accountIDs ...int64) error {
+	for _, id := range accountIDs {
+		if accounts[id].IsFrozen {
+			return fmt.Errorf("%w: account [%d]", ErrAccountFrozen, id)
+		}
+	}
 
-	return result, err
+	return nil
 }
 
 func addMoney(
 	ctx context.Context,
-	q *Queries,
+	q Querier,
 	accountID1 int64,
 	amount1 int64,
 	accountID2 int64,
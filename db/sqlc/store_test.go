@@ -2,17 +2,23 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mateusribs/simple_bank/money"
+	"github.com/mateusribs/simple_bank/util"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTransferTx(t *testing.T){
-	store := NewStore(testDB)
+	store := NewStore(testDB, StoreOptions{})
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := createFundedAccount(t)
+	account2 := createCurrencyAccount(t, account1.Currency, util.RandomMoney())
 
 	//run n concurrent transfer transactions
 	n := 5
@@ -115,10 +121,10 @@ func TestTransferTx(t *testing.T){
 }
 
 func TestTransferTxDeadLock(t *testing.T){
-	store := NewStore(testDB)
+	store := NewStore(testDB, StoreOptions{})
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := createFundedAccount(t)
+	account2 := createCurrencyAccount(t, account1.Currency, 1000)
 
 	//run n concurrent transfer transactions
 	n := 10
@@ -162,4 +168,239 @@ func TestTransferTxDeadLock(t *testing.T){
 	fmt.Println("balance after tx:", updatedAccount1.Balance, updatedAccount2.Balance)
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
-}
\ No newline at end of file
+}
+
+func TestTransferTxCurrencyMismatch(t *testing.T){
+	store := NewStore(testDB, StoreOptions{})
+
+	account1 := createRandomAccount(t)
+	account2 := createCurrencyAccount(t, randomUnusedCurrency(), util.RandomMoney())
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID: account2.ID,
+		Amount: 10,
+	})
+	require.ErrorIs(t, err, money.ErrCurrencyMismatch)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
+
+func TestTransferTxLimits(t *testing.T){
+	store := NewStore(testDB, StoreOptions{})
+
+	account1 := createFundedAccount(t)
+	account2 := createCurrencyAccount(t, account1.Currency, 1000)
+
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID: sql.NullInt64{Int64: account1.ID, Valid: true},
+		MaxPerTransfer: 50,
+		MaxDailyAmount: 100,
+		MaxDailyCount: 3,
+	})
+	require.NoError(t, err)
+
+	transfer := func(amount int64) error {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID: account2.ID,
+			Amount: amount,
+		})
+		return err
+	}
+
+	var limitErr *TransferLimitError
+
+	// above the per transfer limit
+	err = transfer(60)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitPerTransfer, limitErr.Limit)
+
+	require.NoError(t, transfer(50))
+	require.NoError(t, transfer(40))
+
+	// 90 already used out of 100
+	err = transfer(20)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitDailyAmount, limitErr.Limit)
+	require.Equal(t, int64(90), limitErr.Used)
+
+	require.NoError(t, transfer(10))
+
+	// third transfer of the day already done
+	err = transfer(1)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitDailyCount, limitErr.Limit)
+
+	// the limits do not apply to the receiving account
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID: account1.ID,
+		Amount: 500,
+	})
+	require.NoError(t, err)
+}
+
+func TestCompleteTransferChallengeTx(t *testing.T){
+	store := NewStore(testDB, StoreOptions{})
+
+	account1 := createFundedAccount(t)
+	account2 := createCurrencyAccount(t, account1.Currency, util.RandomMoney())
+	amount := int64(10)
+
+	challenge, err := testQueries.CreateTransferChallenge(context.Background(), CreateTransferChallengeParams{
+		ID: uuid.New(),
+		Username: account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID: account2.ID,
+		Amount: amount,
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, ChallengePending, challenge.Status)
+
+	result, err := store.CompleteTransferChallengeTx(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.Equal(t, amount, result.Transfer.Amount)
+	require.Equal(t, account1.Balance-amount, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+amount, result.ToAccount.Balance)
+
+	completed, err := testQueries.GetTransferChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.Equal(t, ChallengeCompleted, completed.Status)
+	require.Equal(t, result.Transfer.ID, completed.TransferID.Int64)
+
+	// a challenge runs only once
+	_, err = store.CompleteTransferChallengeTx(context.Background(), challenge.ID)
+	require.ErrorIs(t, err, ErrChallengeCompleted)
+
+	expired, err := testQueries.CreateTransferChallenge(context.Background(), CreateTransferChallengeParams{
+		ID: uuid.New(),
+		Username: account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID: account2.ID,
+		Amount: amount,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, err = store.CompleteTransferChallengeTx(context.Background(), expired.ID)
+	require.ErrorIs(t, err, ErrChallengeExpired)
+}
+
+func TestTransferTxFrozenAccount(t *testing.T){
+	store := NewStore(testDB, StoreOptions{})
+
+	account1 := createRandomAccount(t)
+	account2 := createCurrencyAccount(t, account1.Currency, util.RandomMoney())
+
+	_, err := testQueries.SetAccountFrozen(context.Background(), SetAccountFrozenParams{ID: account2.ID, IsFrozen: true})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID: account2.ID,
+		Amount: 10,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID: account1.ID,
+		Amount: 10,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+}
+
+func TestTransferTxConcurrent(t *testing.T){
+	store := NewStore(testDB, StoreOptions{})
+
+	account1 := createFundedAccount(t)
+	accounts := []Account{
+		account1,
+		createCurrencyAccount(t, account1.Currency, 1000),
+		createCurrencyAccount(t, account1.Currency, 1000),
+	}
+
+	// every account sends and receives the same amount, in both directions of every pair
+	rounds := 10
+	amount := int64(1)
+
+	errs := make(chan error)
+	n := 0
+
+	for i := 0; i < rounds; i++ {
+		for _, from := range accounts {
+			for _, to := range accounts {
+				if from.ID == to.ID {
+					continue
+				}
+
+				n++
+
+				go func(fromAccountID, toAccountID int64) {
+					_, err := store.TransferTx(context.Background(), TransferTxParams{
+						FromAccountID: fromAccountID,
+						ToAccountID: toAccountID,
+						Amount: amount,
+					})
+
+					errs <- err
+				}(from.ID, to.ID)
+			}
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	for _, account := range accounts {
+		updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, updatedAccount.Balance)
+	}
+}
+
+func TestExecTxRetriesSerializationFailure(t *testing.T){
+	store := NewStore(testDB, StoreOptions{}).(*SQLStore)
+	account := createRandomAccount(t)
+
+	// each transaction reads the balance and writes it back incremented, which conflicts at serializable isolation
+	n := 10
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			errs <- store.execTx(context.Background(), pgx.TxOptions{IsoLevel: pgx.Serializable}, func(q Querier) error {
+				current, err := q.GetAccount(context.Background(), account.ID)
+
+				if err != nil {
+					return err
+				}
+
+				_, err = q.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Owner: account.Owner, Balance: current.Balance + 1})
+
+				return err
+			})
+		}()
+	}
+
+	failed := 0
+
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			require.True(t, isRetryable(err), err)
+			failed++
+		}
+	}
+
+	// a transaction can lose every attempt, but every committed one is counted exactly once
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+int64(n-failed), updatedAccount.Balance)
+	require.Less(t, failed, n)
+}
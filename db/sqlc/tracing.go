@@ -0,0 +1,187 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/mateusribs/simple_bank/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// span attributes of the accounts a transfer touches
+const (
+	fromAccountIDKey = attribute.Key("transfer.from_account_id")
+	toAccountIDKey = attribute.Key("transfer.to_account_id")
+	replicaKey = attribute.Key("db.replica")
+)
+
+// wraps a connection or transaction, opening a span named after the sqlc query for every statement
+type tracedDBTX struct {
+	db DBTX
+	// span of the transaction, the parent of its statements even though the callbacks of execTx
+	// run them with the context of the caller
+	txSpan trace.Span
+	// bounds each statement unless the context already ends sooner; zero leaves statements unbounded
+	timeout time.Duration
+	// marks the spans of statements a read-only replica ran
+	replica bool
+}
+
+func (t tracedDBTX) Exec(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+
+	ctx, span := t.startQuerySpan(ctx, query)
+	tag, err := t.db.Exec(ctx, query, args...)
+	endSpan(span, err)
+	return tag, err
+}
+
+// pgx reports some query errors only while reading the rows, so the span and the timeout end when they are closed
+func (t tracedDBTX) Query(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error) {
+	ctx, cancel := t.withTimeout(ctx)
+
+	ctx, span := t.startQuerySpan(ctx, query)
+	rows, err := t.db.Query(ctx, query, args...)
+
+	if err != nil {
+		endSpan(span, err)
+		cancel()
+		return nil, err
+	}
+
+	return &tracedRows{Rows: rows, span: span, cancel: cancel}, nil
+}
+
+// the query runs when the row is scanned
+func (t tracedDBTX) QueryRow(ctx context.Context, query string, args ...interface{}) pgx.Row {
+	ctx, cancel := t.withTimeout(ctx)
+
+	ctx, span := t.startQuerySpan(ctx, query)
+
+	return tracedRow{row: t.db.QueryRow(ctx, query, args...), span: span, cancel: cancel}
+}
+
+type tracedRows struct {
+	pgx.Rows
+	span trace.Span
+	cancel context.CancelFunc
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+
+	// Close runs again on every deferred call after the rows were read
+	if r.cancel != nil {
+		endSpan(r.span, r.Rows.Err())
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+type tracedRow struct {
+	row pgx.Row
+	span trace.Span
+	cancel context.CancelFunc
+}
+
+// reports a missing row as sql.ErrNoRows, which the store and its callers check for since before the move to pgx
+func (r tracedRow) Scan(dest ...any) error {
+	defer r.cancel()
+
+	err := r.row.Scan(dest...)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		// not a failure of the query
+		endSpan(r.span, nil)
+		return sql.ErrNoRows
+	}
+
+	endSpan(r.span, err)
+	return err
+}
+
+func (t tracedDBTX) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if t.timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= t.timeout {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, t.timeout)
+}
+
+func (t tracedDBTX) startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	name, operation := describeQuery(query)
+
+	if t.txSpan != nil {
+		ctx = trace.ContextWithSpan(ctx, t.txSpan)
+	}
+
+	return tracing.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(operation),
+			semconv.DBStatement(query),
+			replicaKey.Bool(t.replica),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// the query name from the "-- name: GetAccount :one" header sqlc generates, and the SQL verb of the statement
+func describeQuery(query string) (name string, operation string) {
+	for _, line := range strings.Split(query, "\n") {
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+
+		if header, ok := strings.CutPrefix(line, "-- name:"); ok {
+			if fields := strings.Fields(header); len(fields) > 0 {
+				name = fields[0]
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "--") {
+			continue
+		}
+
+		if fields := strings.Fields(line); len(fields) > 0 {
+			operation = strings.ToUpper(fields[0])
+		}
+		break
+	}
+
+	if name == "" {
+		name = operation
+	}
+
+	return name, operation
+}
+
+// the span of a store method, tagged with the records it touches
+func startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeQuery(t *testing.T) {
+	name, operation := describeQuery(getAccount)
+	require.Equal(t, "GetAccount", name)
+	require.Equal(t, "SELECT", operation)
+
+	name, operation = describeQuery(createTransfer)
+	require.Equal(t, "CreateTransfer", name)
+	require.Equal(t, "INSERT", operation)
+
+	// statements written by hand have no name header
+	name, operation = describeQuery("\n  update accounts set balance = 0")
+	require.Equal(t, "UPDATE", name)
+	require.Equal(t, "UPDATE", operation)
+}
+
+// records the context every statement runs with
+type deadlineDBTX struct {
+	DBTX
+	ctx context.Context
+}
+
+func (d *deadlineDBTX) Exec(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
+	d.ctx = ctx
+	return pgconn.CommandTag{}, nil
+}
+
+func TestQueryTimeout(t *testing.T) {
+	db := &deadlineDBTX{}
+
+	_, err := tracedDBTX{db: db, timeout: time.Second}.Exec(context.Background(), getAccount)
+	require.NoError(t, err)
+
+	deadline, ok := db.ctx.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+
+	// a caller that needs the answer sooner keeps its own deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = tracedDBTX{db: db, timeout: time.Second}.Exec(ctx, getAccount)
+	require.NoError(t, err)
+	expected, _ := ctx.Deadline()
+	deadline, _ = db.ctx.Deadline()
+	require.Equal(t, expected, deadline)
+
+	_, err = tracedDBTX{db: db}.Exec(context.Background(), getAccount)
+	require.NoError(t, err)
+
+	_, ok = db.ctx.Deadline()
+	require.False(t, ok)
+}
+
+type noRowsDBTX struct {
+	DBTX
+}
+
+type noRow struct{}
+
+func (noRow) Scan(dest ...any) error {
+	return pgx.ErrNoRows
+}
+
+func (noRowsDBTX) QueryRow(ctx context.Context, query string, args ...interface{}) pgx.Row {
+	return noRow{}
+}
+
+func TestQueryRowNoRows(t *testing.T) {
+	_, err := New(tracedDBTX{db: noRowsDBTX{}, timeout: time.Second}).GetAccount(context.Background(), 1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -19,6 +19,7 @@ func createRandomTransfer(t *testing.T) Transfer {
 		FromAccountID: account1.ID,
 		ToAccountID: account2.ID,
 		Amount: util.RandomMoney(),
+		Fee: util.RandomInt(0, 10),
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -29,6 +30,7 @@ func createRandomTransfer(t *testing.T) Transfer {
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.Fee, transfer.Fee)
 
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// contains input parameters of the reset password transaction
+type ResetPasswordTxParams struct {
+	Username string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+// contains results of the reset password transaction
+type ResetPasswordTxResult struct {
+	User User `json:"user"`
+	// sessions that were still usable and no longer renew access tokens
+	BlockedSessions int64 `json:"blocked_sessions"`
+}
+
+// sets a new password for the user and blocks every session of the user within a single database transaction,
+// so refresh tokens issued before a reset stop working with the old password
+func (store *txStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error) {
+	var result ResetPasswordTxResult
+
+	ctx, span := startSpan(ctx, "ResetPasswordTx", attribute.String("user.username", arg.Username))
+
+	err := store.execTx(ctx, pgx.TxOptions{}, func(q Querier) error {
+		var err error
+
+		result.User, err = q.ResetUserPassword(ctx, ResetUserPasswordParams{
+			Username: arg.Username,
+			HashedPassword: arg.HashedPassword,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.BlockedSessions, err = q.BlockUserSessions(ctx, arg.Username)
+
+		return err
+	})
+
+	endSpan(span, err)
+
+	return result, err
+}
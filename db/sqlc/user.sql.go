@@ -17,7 +17,7 @@ INSERT INTO users (
     email
 ) VALUES (
     $1, $2, $3, $4
-) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, tier, totp_secret, totp_pending_secret, totp_last_counter
 `
 
 type CreateUserParams struct {
@@ -28,7 +28,7 @@ type CreateUserParams struct {
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser,
+	row := q.db.QueryRow(ctx, createUser,
 		arg.Username,
 		arg.HashedPassword,
 		arg.FullName,
@@ -42,17 +42,22 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.Tier,
+		&i.TotpSecret,
+		&i.TotpPendingSecret,
+		&i.TotpLastCounter,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at FROM users
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, tier, totp_secret, totp_pending_secret, totp_last_counter FROM users
 WHERE username = $1 LIMIT 1
 `
 
 func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUser, username)
+	row := q.db.QueryRow(ctx, getUser, username)
 	var i User
 	err := row.Scan(
 		&i.Username,
@@ -61,6 +66,260 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.Tier,
+		&i.TotpSecret,
+		&i.TotpPendingSecret,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const updateUserHashedPassword = `-- name: UpdateUserHashedPassword :one
+UPDATE users
+SET hashed_password = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, tier, totp_secret, totp_pending_secret, totp_last_counter
+`
+
+type UpdateUserHashedPasswordParams struct {
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+func (q *Queries) UpdateUserHashedPassword(ctx context.Context, arg UpdateUserHashedPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserHashedPassword, arg.Username, arg.HashedPassword)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Tier,
+		&i.TotpSecret,
+		&i.TotpPendingSecret,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const resetUserPassword = `-- name: ResetUserPassword :one
+UPDATE users
+SET hashed_password = $2, password_changed_at = now()
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, tier, totp_secret, totp_pending_secret, totp_last_counter
+`
+
+type ResetUserPasswordParams struct {
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+func (q *Queries) ResetUserPassword(ctx context.Context, arg ResetUserPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, resetUserPassword, arg.Username, arg.HashedPassword)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Tier,
+		&i.TotpSecret,
+		&i.TotpPendingSecret,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, tier, totp_secret, totp_pending_secret, totp_last_counter
+`
+
+type UpdateUserRoleParams struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.Username, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Tier,
+		&i.TotpSecret,
+		&i.TotpPendingSecret,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const updateUserTOTPSecret = `-- name: UpdateUserTOTPSecret :one
+UPDATE users
+SET totp_secret = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, tier, totp_secret, totp_pending_secret, totp_last_counter
+`
+
+type UpdateUserTOTPSecretParams struct {
+	Username   string `json:"username"`
+	TotpSecret string `json:"totp_secret"`
+}
+
+func (q *Queries) UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserTOTPSecret, arg.Username, arg.TotpSecret)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Tier,
+		&i.TotpSecret,
+		&i.TotpPendingSecret,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const updateUserTier = `-- name: UpdateUserTier :one
+UPDATE users
+SET tier = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, tier, totp_secret, totp_pending_secret, totp_last_counter
+`
+
+type UpdateUserTierParams struct {
+	Username string `json:"username"`
+	Tier     string `json:"tier"`
+}
+
+func (q *Queries) UpdateUserTier(ctx context.Context, arg UpdateUserTierParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserTier, arg.Username, arg.Tier)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Tier,
+		&i.TotpSecret,
+		&i.TotpPendingSecret,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const updateUserTOTPPendingSecret = `-- name: UpdateUserTOTPPendingSecret :one
+UPDATE users
+SET totp_pending_secret = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, tier, totp_secret, totp_pending_secret, totp_last_counter
+`
+
+type UpdateUserTOTPPendingSecretParams struct {
+	Username          string `json:"username"`
+	TotpPendingSecret string `json:"totp_pending_secret"`
+}
+
+func (q *Queries) UpdateUserTOTPPendingSecret(ctx context.Context, arg UpdateUserTOTPPendingSecretParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserTOTPPendingSecret, arg.Username, arg.TotpPendingSecret)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Tier,
+		&i.TotpSecret,
+		&i.TotpPendingSecret,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const confirmUserTOTPSecret = `-- name: ConfirmUserTOTPSecret :one
+UPDATE users
+SET totp_secret = totp_pending_secret, totp_pending_secret = '', totp_last_counter = $3
+WHERE username = $1 AND totp_pending_secret = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, tier, totp_secret, totp_pending_secret, totp_last_counter
+`
+
+type ConfirmUserTOTPSecretParams struct {
+	Username          string `json:"username"`
+	TotpPendingSecret string `json:"totp_pending_secret"`
+	TotpLastCounter   int64  `json:"totp_last_counter"`
+}
+
+func (q *Queries) ConfirmUserTOTPSecret(ctx context.Context, arg ConfirmUserTOTPSecretParams) (User, error) {
+	row := q.db.QueryRow(ctx, confirmUserTOTPSecret, arg.Username, arg.TotpPendingSecret, arg.TotpLastCounter)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Tier,
+		&i.TotpSecret,
+		&i.TotpPendingSecret,
+		&i.TotpLastCounter,
+	)
+	return i, err
+}
+
+const useUserTOTPCounter = `-- name: UseUserTOTPCounter :one
+UPDATE users
+SET totp_last_counter = $2
+WHERE username = $1 AND totp_last_counter < $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, tier, totp_secret, totp_pending_secret, totp_last_counter
+`
+
+type UseUserTOTPCounterParams struct {
+	Username        string `json:"username"`
+	TotpLastCounter int64  `json:"totp_last_counter"`
+}
+
+func (q *Queries) UseUserTOTPCounter(ctx context.Context, arg UseUserTOTPCounterParams) (User, error) {
+	row := q.db.QueryRow(ctx, useUserTOTPCounter, arg.Username, arg.TotpLastCounter)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Tier,
+		&i.TotpSecret,
+		&i.TotpPendingSecret,
+		&i.TotpLastCounter,
 	)
 	return i, err
 }
@@ -34,6 +34,9 @@ func createRandomUser(t *testing.T) User {
 
 	require.NotZero(t, user.CreatedAt)
 	require.True(t, user.PasswordChangedAt.IsZero())
+	require.Equal(t, util.CustomerRole, user.Role)
+	require.Equal(t, "standard", user.Tier)
+	require.Empty(t, user.TotpSecret)
 
 	return user
 }
@@ -55,4 +58,36 @@ func TestGetUser(t *testing.T){
 	require.Equal(t, user1.Email, user2.Email)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
-}
\ No newline at end of file
+}
+func TestUpdateUserHashedPassword(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	user2, err := testQueries.UpdateUserHashedPassword(context.Background(), UpdateUserHashedPasswordParams{
+		Username: user1.Username,
+		HashedPassword: hashedPassword,
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, user2)
+
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, hashedPassword, user2.HashedPassword)
+	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	user1 := createRandomUser(t)
+	require.Equal(t, util.CustomerRole, user1.Role)
+
+	user2, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Username: user1.Username,
+		Role: util.AdminRole,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, util.AdminRole, user2.Role)
+}
@@ -0,0 +1,84 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	if err := server.validateCurrency(req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	product := req.GetProduct()
+
+	if product == "" {
+		product = db.ProductChecking
+	} else if !isAlphanumeric(product) {
+		return nil, invalidArgument(errors.New("product must contain only letters and digits"))
+	}
+
+	account, err := server.store.CreateAccount(ctx, db.CreateAccountParams{
+		Owner: authPayload(ctx).Username,
+		Currency: req.GetCurrency(),
+		Balance: 0,
+		Product: product,
+	})
+
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	if err := validateAccountID("id", req.GetId()); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	account, err := server.store.GetAccount(ctx, req.GetId())
+
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if account.Owner != authPayload(ctx).Username {
+		return nil, status.Error(codes.PermissionDenied, "account does not belong to the authenticated user")
+	}
+
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	if req.GetPageId() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "page id must be positive")
+	}
+
+	if req.GetPageSize() < 5 || req.GetPageSize() > 10 {
+		return nil, status.Error(codes.InvalidArgument, "page size must be between 5 and 10")
+	}
+
+	accounts, err := server.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner: authPayload(ctx).Username,
+		Limit: req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, len(accounts))}
+
+	for i, account := range accounts {
+		rsp.Accounts[i] = convertAccount(account)
+	}
+
+	return rsp, nil
+}
@@ -0,0 +1,116 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	mockdb "github.com/mateusribs/simple_bank/db/mock"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/pb"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	username := util.RandomOwner()
+	account := db.Account{ID: 1, Owner: username, Currency: util.USD}
+
+	testCases := []struct{
+		name string
+		setupContext func(t *testing.T, server *Server) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		code codes.Code
+	}{
+		{
+			name: "OK",
+			setupContext: func(t *testing.T, server *Server) context.Context {
+				return withBearer(t, server, username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "NoAuthorization",
+			setupContext: func(t *testing.T, server *Server) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "UnsupportedAuthorization",
+			setupContext: func(t *testing.T, server *Server) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "basic abc")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "InvalidToken",
+			setupContext: func(t *testing.T, server *Server) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "bearer invalid")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "OtherUsersAccount",
+			setupContext: func(t *testing.T, server *Server) context.Context {
+				return withBearer(t, server, "someoneelse")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			code: codes.PermissionDenied,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			rsp, err := client.GetAccount(tc.setupContext(t, server), &pb.GetAccountRequest{Id: account.ID})
+			require.Equal(t, tc.code, status.Code(err))
+
+			if tc.code == codes.OK {
+				require.Equal(t, account.Owner, rsp.GetAccount().GetOwner())
+			}
+		})
+	}
+}
+
+func TestPublicMethodsSkipAuthorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+
+	server := newTestServer(t, store)
+	client := newTestClient(t, server)
+
+	_, err := client.LoginUser(context.Background(), &pb.LoginUserRequest{Username: util.RandomOwner(), Password: "secret"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,55 @@
+package gapi
+
+import (
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username: user.Username,
+		FullName: user.FullName,
+		Email: user.Email,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt: timestamppb.New(user.CreatedAt),
+	}
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	rsp := &pb.Account{
+		Id: account.ID,
+		Owner: account.Owner,
+		Balance: account.Balance,
+		AvailableBalance: account.AvailableBalance,
+		Currency: account.Currency,
+		Product: account.Product,
+	}
+
+	if account.CreatedAt.Valid {
+		rsp.CreatedAt = timestamppb.New(account.CreatedAt.Time)
+	}
+
+	return rsp
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id: transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId: transfer.ToAccountID,
+		Amount: transfer.Amount,
+		Fee: transfer.Fee,
+		CreatedAt: timestamppb.New(transfer.CreatedAt),
+	}
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id: entry.ID,
+		AccountId: entry.AccountID,
+		Amount: entry.Amount,
+		Type: entry.Type,
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+	}
+}
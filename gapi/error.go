@@ -0,0 +1,92 @@
+package gapi
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mateusribs/simple_bank/auth"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/money"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maps store errors to the status codes matching the ones of the HTTP API
+func storeError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	var limitErr *db.TransferLimitError
+
+	if errors.As(err, &limitErr) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	for _, domainErr := range domainErrors {
+		if errors.Is(err, domainErr.err) {
+			return status.Error(domainErr.code, err.Error())
+		}
+	}
+
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.UniqueViolation:
+			return status.Error(codes.AlreadyExists, "a resource with the same unique fields already exists")
+		case pgerrcode.ForeignKeyViolation:
+			return status.Error(codes.NotFound, "a referenced resource does not exist")
+		}
+	}
+
+	return internalError(err)
+}
+
+// sentinel errors whose messages are safe to show, with the codes closest to the statuses the HTTP API uses
+var domainErrors = []struct{
+	err error
+	code codes.Code
+}{
+	{money.ErrUnknownCurrency, codes.InvalidArgument},
+	{money.ErrCurrencyMismatch, codes.InvalidArgument},
+	{money.ErrInvalidAmount, codes.InvalidArgument},
+	{money.ErrOverflow, codes.OutOfRange},
+	{db.ErrInsufficientFunds, codes.FailedPrecondition},
+	{db.ErrCaptureExceedsHold, codes.FailedPrecondition},
+	{db.ErrHoldNotAuthorized, codes.FailedPrecondition},
+	{db.ErrHoldExpired, codes.FailedPrecondition},
+	{db.ErrChallengeCompleted, codes.FailedPrecondition},
+	{db.ErrChallengeExpired, codes.FailedPrecondition},
+	{db.ErrAccountFrozen, codes.PermissionDenied},
+	{auth.ErrTOTPNotEnrolled, codes.FailedPrecondition},
+	{auth.ErrInvalidTOTPCode, codes.Unauthenticated},
+}
+
+// an Internal status sent with a generic message; loggerInterceptor logs the cause
+type serverError struct {
+	cause error
+}
+
+func internalError(err error) error {
+	return &serverError{cause: err}
+}
+
+func (err *serverError) Error() string {
+	return err.cause.Error()
+}
+
+func (err *serverError) Unwrap() error {
+	return err.cause
+}
+
+// read by grpc to build the status sent to the client
+func (err *serverError) GRPCStatus() *status.Status {
+	return status.New(codes.Internal, "internal server error")
+}
+
+func invalidArgument(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
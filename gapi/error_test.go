@@ -0,0 +1,84 @@
+package gapi
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStoreError(t *testing.T) {
+	testCases := []struct{
+		name string
+		err error
+		code codes.Code
+		message string
+	}{
+		{
+			name: "NotFound",
+			err: sql.ErrNoRows,
+			code: codes.NotFound,
+		},
+		{
+			name: "UniqueViolation",
+			err: &pgconn.PgError{Code: pgerrcode.UniqueViolation, ConstraintName: "users_email_key"},
+			code: codes.AlreadyExists,
+			message: "a resource with the same unique fields already exists",
+		},
+		{
+			name: "ForeignKeyViolation",
+			err: &pgconn.PgError{Code: pgerrcode.ForeignKeyViolation, ConstraintName: "accounts_owner_fkey"},
+			code: codes.NotFound,
+			message: "a referenced resource does not exist",
+		},
+		{
+			name: "AccountFrozen",
+			err: fmt.Errorf("%w: account [1]", db.ErrAccountFrozen),
+			code: codes.PermissionDenied,
+			message: "account is frozen: account [1]",
+		},
+		{
+			name: "InsufficientFunds",
+			err: fmt.Errorf("%w: account [1]", db.ErrInsufficientFunds),
+			code: codes.FailedPrecondition,
+		},
+		{
+			name: "ChallengeExpired",
+			err: db.ErrChallengeExpired,
+			code: codes.FailedPrecondition,
+		},
+		{
+			// the cause is logged, not sent
+			name: "Internal",
+			err: errors.New("connection refused by 10.0.0.5:5432"),
+			code: codes.Internal,
+			message: "internal server error",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := storeError(tc.err)
+
+			rsp, ok := status.FromError(err)
+			require.True(t, ok)
+			require.Equal(t, tc.code, rsp.Code())
+
+			if tc.message != "" {
+				require.Equal(t, tc.message, rsp.Message())
+			}
+		})
+	}
+
+	// loggerInterceptor logs what storeError hid
+	require.ErrorContains(t, storeError(errors.New("connection refused")), "connection refused")
+}
@@ -0,0 +1,70 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mateusribs/simple_bank/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	requestIDHeaderKey = "x-request-id"
+	// longest client supplied request ID that is kept; longer ones are replaced
+	maxRequestIDLength = 128
+)
+
+// codes that mean the server failed rather than the client
+var serverErrorCodes = map[codes.Code]bool{
+	codes.Unknown: true,
+	codes.Internal: true,
+	codes.Unavailable: true,
+	codes.DataLoss: true,
+	codes.Unimplemented: true,
+}
+
+// tags the call with the x-request-id metadata of the client or a new one, echoes it in the header,
+// and logs the call once it completes
+func loggerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		requestID := incomingRequestID(ctx)
+
+		ctx = logger.WithRequestID(ctx, requestID)
+		// fails only outside of a real stream, as in tests
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeaderKey, requestID))
+
+		rsp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		log := logger.FromContext(ctx)
+		event := log.Info()
+
+		if serverErrorCodes[code] {
+			event = log.Error().Err(err)
+		}
+
+		event.
+			Str("protocol", "grpc").
+			Str("method", info.FullMethod).
+			Str("status_code", code.String()).
+			Dur("duration", time.Since(start)).
+			Msg("request completed")
+
+		return rsp, err
+	}
+}
+
+func incomingRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDHeaderKey); len(values) > 0 && values[0] != "" && len(values[0]) <= maxRequestIDLength {
+			return values[0]
+		}
+	}
+
+	return uuid.NewString()
+}
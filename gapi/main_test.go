@@ -0,0 +1,82 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/pb"
+	"github.com/mateusribs/simple_bank/token"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// shared by every test server, so tests can seal secrets before the server exists
+var testTOTPEncryptionKey = util.RandomString(32)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey: util.RandomString(32),
+		TOTPEncryptionKey: testTOTPEncryptionKey,
+		AccessTokenDuration: time.Minute,
+		StepUpTokenDuration: 5 * time.Minute,
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	server, err := NewServer(config, store, tokenMaker, db.NewCurrencyCatalog())
+	require.NoError(t, err)
+
+	return server
+}
+
+// serves the server over an in-memory connection and returns a client for it
+func newTestClient(t *testing.T, server *Server) pb.SimpleBankClient {
+	listener := bufconn.Listen(1024 * 1024)
+
+	go server.grpcServer.Serve(listener)
+	t.Cleanup(server.grpcServer.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewSimpleBankClient(conn)
+}
+
+// a context carrying the payload the interceptor would store, for calling the handlers directly
+func authContext(t *testing.T, server *Server, username string, elevated bool) context.Context {
+	var payload *token.Payload
+	var err error
+
+	if elevated {
+		_, payload, err = server.tokenMaker.CreateElevatedToken(username, time.Minute)
+	} else {
+		_, payload, err = server.tokenMaker.CreateToken(username, time.Minute)
+	}
+	require.NoError(t, err)
+
+	return context.WithValue(context.Background(), authPayloadKey{}, payload)
+}
+
+func withBearer(t *testing.T, server *Server, username string) context.Context {
+	accessToken, _, err := server.tokenMaker.CreateToken(username, time.Minute)
+	require.NoError(t, err)
+
+	bearer := fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken)
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, bearer)
+}
@@ -0,0 +1,89 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/pb"
+	"github.com/mateusribs/simple_bank/token"
+	"github.com/mateusribs/simple_bank/util"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// serves gRPC requests for banking services
+type Server struct {
+	pb.UnimplementedSimpleBankServer
+	config util.Config
+	store db.Store
+	tokenMaker token.Maker
+	passwordHasher util.PasswordHasher
+	passwordPolicy *util.PasswordPolicy
+	currencies *db.CurrencyCatalog
+	grpcServer *grpc.Server
+}
+
+// create a new gRPC server sharing the store, token maker and currency catalogue of the HTTP server
+func NewServer(config util.Config, store db.Store, tokenMaker token.Maker, currencies *db.CurrencyCatalog) (*Server, error) {
+	passwordHasher, err := util.NewPasswordHasher(config)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher: %w", err)
+	}
+
+	passwordPolicy, err := util.NewPasswordPolicy(config)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password policy: %w", err)
+	}
+
+	server := &Server{
+		config: config,
+		store: store,
+		tokenMaker: tokenMaker,
+		passwordHasher: passwordHasher,
+		passwordPolicy: passwordPolicy,
+		currencies: currencies,
+	}
+
+	server.grpcServer = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor(), loggerInterceptor(), authInterceptor(tokenMaker)),
+	)
+	pb.RegisterSimpleBankServer(server.grpcServer, server)
+	reflection.Register(server.grpcServer)
+
+	return server, nil
+}
+
+// runs gRPC server on a specific address
+func (server *Server) Start(address string) error {
+	listener, err := net.Listen("tcp", address)
+
+	if err != nil {
+		return fmt.Errorf("cannot create listener: %w", err)
+	}
+
+	return server.grpcServer.Serve(listener)
+}
+
+// stops accepting new calls and waits for the running ones to finish, closing the connections left open once ctx expires
+func (server *Server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+
+	go func() {
+		server.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.grpcServer.Stop()
+		<-stopped
+		return ctx.Err()
+	}
+}
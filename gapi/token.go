@@ -0,0 +1,88 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/mateusribs/simple_bank/auth"
+	"github.com/mateusribs/simple_bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	if req.GetRefreshToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh token is required")
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.GetRefreshToken())
+
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.ID)
+
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	switch {
+	case session.IsBlocked:
+		return nil, status.Error(codes.Unauthenticated, "blocked session")
+	case session.Username != refreshPayload.Username:
+		return nil, status.Error(codes.Unauthenticated, "incorrect session user")
+	case session.RefreshToken != req.GetRefreshToken():
+		return nil, status.Error(codes.Unauthenticated, "mismatched session token")
+	case time.Now().After(session.ExpiresAt):
+		return nil, status.Error(codes.Unauthenticated, "expired session")
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(refreshPayload.Username, server.config.AccessTokenDuration)
+
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	rsp := &pb.RenewAccessTokenResponse{
+		AccessToken: accessToken,
+		AccessTokenExpiresAt: timestamppb.New(accessPayload.ExpiredAt),
+	}
+
+	return rsp, nil
+}
+
+// re-authenticates the user with the password or a TOTP code and issues a short-lived elevated access token
+func (server *Server) StepUpToken(ctx context.Context, req *pb.StepUpTokenRequest) (*pb.StepUpTokenResponse, error) {
+	if (req.GetPassword() == "") == (req.GetTotpCode() == "") {
+		return nil, status.Error(codes.InvalidArgument, "exactly one of password and totp code is required")
+	}
+
+	user, err := server.store.GetUser(ctx, authPayload(ctx).Username)
+
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if req.GetTotpCode() != "" {
+		if err := auth.CheckTOTP(ctx, server.store, server.config.TOTPEncryptionKey, user, req.GetTotpCode()); err != nil {
+			return nil, storeError(err)
+		}
+	} else if err := server.passwordHasher.CheckPassword(req.GetPassword(), user.HashedPassword); err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateElevatedToken(user.Username, server.config.StepUpTokenDuration)
+
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	rsp := &pb.StepUpTokenResponse{
+		AccessToken: accessToken,
+		AccessTokenExpiresAt: timestamppb.New(accessPayload.ExpiredAt),
+	}
+
+	return rsp, nil
+}
@@ -0,0 +1,90 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	if err := validateAccountID("from account id", req.GetFromAccountId()); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	if err := validateAccountID("to account id", req.GetToAccountId()); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	if req.GetAmount() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
+	}
+
+	if err := server.validateCurrency(req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	payload := authPayload(ctx)
+
+	fromAccount, err := server.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Owner != payload.Username {
+		return nil, status.Error(codes.PermissionDenied, "from account does not belong to the authenticated user")
+	}
+
+	if _, err := server.validAccount(ctx, req.GetToAccountId(), req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	// there are no transfer challenges over gRPC, the caller steps up first
+	if server.requiresStepUp(req.GetAmount()) && !payload.Elevated {
+		return nil, status.Error(codes.PermissionDenied, "an elevated token is required, re-authenticate with StepUpToken")
+	}
+
+	result, err := server.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID: req.GetToAccountId(),
+		Amount: req.GetAmount(),
+	})
+
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.CreateTransferResponse{
+		Transfer: convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount: convertAccount(result.ToAccount),
+		FromEntry: convertEntry(result.FromEntry),
+		ToEntry: convertEntry(result.ToEntry),
+	}
+
+	return rsp, nil
+}
+
+func (server *Server) validAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	// from the primary, since the transfer may use an account created a moment ago
+	account, err := server.store.GetAccount(db.WithReadYourWrites(ctx), accountID)
+
+	if err != nil {
+		return account, storeError(err)
+	}
+
+	if account.Currency != currency {
+		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
+		return account, invalidArgument(err)
+	}
+
+	return account, nil
+}
+
+func (server *Server) requiresStepUp(amount int64) bool {
+	return server.config.StepUpTransferAmount > 0 && amount >= server.config.StepUpTransferAmount
+}
@@ -0,0 +1,189 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	mockdb "github.com/mateusribs/simple_bank/db/mock"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/pb"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateTransferRPC(t *testing.T) {
+	owner := util.RandomOwner()
+	amount := int64(10)
+
+	account1 := db.Account{ID: 1, Owner: owner, Balance: 100, Currency: util.USD}
+	account2 := db.Account{ID: 2, Owner: util.RandomOwner(), Balance: 100, Currency: util.USD}
+	account3 := db.Account{ID: 3, Owner: util.RandomOwner(), Balance: 100, Currency: util.EUR}
+
+	testCases := []struct{
+		name string
+		req *pb.CreateTransferRequest
+		username string
+		elevated bool
+		stepUpAmount int64
+		buildStubs func(store *mockdb.MockStore)
+		code codes.Code
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD},
+			username: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{
+					Transfer: db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount},
+				}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "InvalidAmount",
+			req: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 0, Currency: util.USD},
+			username: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "UnsupportedCurrency",
+			req: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: "JPY"},
+			username: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "FromAccountNotFound",
+			req: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD},
+			username: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "UnauthorizedUser",
+			req: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD},
+			username: account2.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "ToAccountCurrencyMismatch",
+			req: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account3.ID, Amount: amount, Currency: util.USD},
+			username: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "StepUpRequired",
+			req: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD},
+			username: owner,
+			stepUpAmount: amount,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "ElevatedToken",
+			req: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD},
+			username: owner,
+			elevated: true,
+			stepUpAmount: amount,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "TransferLimit",
+			req: &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: amount, Currency: util.USD},
+			username: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{}, &db.TransferLimitError{Limit: db.LimitPerTransfer})
+			},
+			code: codes.ResourceExhausted,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.StepUpTransferAmount = tc.stepUpAmount
+
+			rsp, err := server.CreateTransfer(authContext(t, server, tc.username, tc.elevated), tc.req)
+			require.Equal(t, tc.code, status.Code(err))
+
+			if tc.code == codes.OK {
+				require.NotNil(t, rsp.GetTransfer())
+			}
+		})
+	}
+}
+
+func TestCreateAccountRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	owner := util.RandomOwner()
+
+	arg := db.CreateAccountParams{Owner: owner, Currency: util.EUR, Product: db.ProductChecking}
+	store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).
+		Return(db.Account{ID: 7, Owner: owner, Currency: util.EUR, Product: db.ProductChecking}, nil)
+
+	rsp, err := server.CreateAccount(authContext(t, server, owner, false), &pb.CreateAccountRequest{Currency: util.EUR})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), rsp.GetAccount().GetId())
+
+	_, err = server.CreateAccount(authContext(t, server, owner, false), &pb.CreateAccountRequest{Currency: "JPY"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// disabled in the catalogue shared with the HTTP server
+	server.currencies.Set(db.Currency{Code: util.EUR, Exponent: 2, Enabled: false})
+
+	_, err = server.CreateAccount(authContext(t, server, owner, false), &pb.CreateAccountRequest{Currency: util.EUR})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.ListAccounts(context.Background(), &pb.ListAccountsRequest{PageId: 0, PageSize: 5})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,133 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mateusribs/simple_bank/auth"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/metrics"
+	"github.com/mateusribs/simple_bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	if err := validateUsername(req.GetUsername()); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	if err := validateEmail(req.GetEmail()); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	if req.GetFullName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "full name is required")
+	}
+
+	if err := server.passwordPolicy.Validate(req.GetPassword(), req.GetUsername(), req.GetEmail()); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	hashedPassword, err := server.passwordHasher.HashPassword(req.GetPassword())
+
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	user, err := server.store.CreateUser(ctx, db.CreateUserParams{
+		Username: req.GetUsername(),
+		HashedPassword: hashedPassword,
+		FullName: req.GetFullName(),
+		Email: req.GetEmail(),
+	})
+
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.CreateUserResponse{User: convertUser(user)}, nil
+}
+
+func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	if err := validateUsername(req.GetUsername()); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	user, err := server.store.GetUser(ctx, req.GetUsername())
+
+	if errors.Is(err, sql.ErrNoRows) {
+		metrics.LoginFailures.WithLabelValues("grpc", metrics.LoginUnknownUser).Inc()
+	}
+
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if err := server.passwordHasher.CheckPassword(req.GetPassword(), user.HashedPassword); err != nil {
+		metrics.LoginFailures.WithLabelValues("grpc", metrics.LoginWrongPassword).Inc()
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if server.passwordHasher.NeedsRehash(user.HashedPassword) {
+		auth.RehashPassword(ctx, server.store, server.passwordHasher, user.Username, req.GetPassword())
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.RefreshTokenDuration)
+
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	userAgent, clientIP := clientInfo(ctx)
+
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		ID: refreshPayload.ID,
+		Username: user.Username,
+		RefreshToken: refreshToken,
+		UserAgent: userAgent,
+		ClientIp: clientIP,
+		IsBlocked: false,
+		ExpiresAt: refreshPayload.ExpiredAt,
+	})
+
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	rsp := &pb.LoginUserResponse{
+		User: convertUser(user),
+		SessionId: session.ID.String(),
+		AccessToken: accessToken,
+		RefreshToken: refreshToken,
+		AccessTokenExpiresAt: timestamppb.New(accessPayload.ExpiredAt),
+		RefreshTokenExpiresAt: timestamppb.New(refreshPayload.ExpiredAt),
+	}
+
+	return rsp, nil
+}
+
+// the user agent sent by the client and the address of its connection, recorded on sessions
+func clientInfo(ctx context.Context) (userAgent string, clientIP string) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("user-agent"); len(values) > 0 {
+			userAgent = values[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		clientIP = p.Addr.String()
+	}
+
+	return
+}
@@ -0,0 +1,123 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	mockdb "github.com/mateusribs/simple_bank/db/mock"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/pb"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateUserRPC(t *testing.T) {
+	req := &pb.CreateUserRequest{
+		Username: util.RandomOwner(),
+		FullName: util.RandomOwner(),
+		Email: util.RandomEmail(),
+		Password: util.RandomString(12),
+	}
+
+	testCases := []struct{
+		name string
+		req *pb.CreateUserRequest
+		buildStubs func(store *mockdb.MockStore)
+		code codes.Code
+	}{
+		{
+			name: "OK",
+			req: req,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.User{Username: req.Username, FullName: req.FullName, Email: req.Email}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "InvalidUsername",
+			req: &pb.CreateUserRequest{Username: "invalid-user#1", FullName: req.FullName, Email: req.Email, Password: req.Password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "InvalidEmail",
+			req: &pb.CreateUserRequest{Username: req.Username, FullName: req.FullName, Email: "invalid-email", Password: req.Password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "DuplicateUsername",
+			req: req,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.User{}, &pgconn.PgError{Code: "23505"})
+			},
+			code: codes.AlreadyExists,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			rsp, err := server.CreateUser(context.Background(), tc.req)
+			require.Equal(t, tc.code, status.Code(err))
+
+			if tc.code == codes.OK {
+				require.Equal(t, tc.req.Username, rsp.GetUser().GetUsername())
+			}
+		})
+	}
+}
+
+func TestLoginUserRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	password := util.RandomString(12)
+	hashedPassword, err := server.passwordHasher.HashPassword(password)
+	require.NoError(t, err)
+
+	user := db.User{Username: util.RandomOwner(), HashedPassword: hashedPassword, Email: util.RandomEmail()}
+
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(2).Return(user, nil)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+			return db.Session{ID: arg.ID, Username: arg.Username}, nil
+		})
+
+	client := newTestClient(t, server)
+
+	rsp, err := client.LoginUser(context.Background(), &pb.LoginUserRequest{Username: user.Username, Password: password})
+	require.NoError(t, err)
+	require.NotEmpty(t, rsp.GetAccessToken())
+	require.NotEmpty(t, rsp.GetSessionId())
+
+	// the access token works on authenticated methods
+	payload, err := server.tokenMaker.VerifyToken(rsp.GetAccessToken())
+	require.NoError(t, err)
+	require.Equal(t, user.Username, payload.Username)
+
+	_, err = client.LoginUser(context.Background(), &pb.LoginUserRequest{Username: user.Username, Password: "wrong"})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,39 @@
+package gapi
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+)
+
+var isAlphanumeric = regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString
+
+func validateUsername(username string) error {
+	if !isAlphanumeric(username) {
+		return fmt.Errorf("username must contain only letters and digits")
+	}
+	return nil
+}
+
+func validateEmail(email string) error {
+	if _, err := mail.ParseAddress(email); err != nil {
+		return fmt.Errorf("email is not a valid address")
+	}
+	return nil
+}
+
+func validateAccountID(field string, id int64) error {
+	if id < 1 {
+		return fmt.Errorf("%s must be positive", field)
+	}
+	return nil
+}
+
+// accepts the currencies enabled in the catalogue; returns a status error
+func (server *Server) validateCurrency(code string) error {
+	if !server.currencies.IsEnabled(code) {
+		return invalidArgument(fmt.Errorf("currency %q is not supported", code))
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/mateusribs/simple_bank/util"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// field names shared by the HTTP and gRPC request logs
+const (
+	RequestIDKey = "request_id"
+	UsernameKey = "username"
+	TraceIDKey = "trace_id"
+)
+
+// configures the global logger: JSON to stdout, or colored text in development, with sensitive fields redacted
+func Setup(config util.Config) error {
+	level := zerolog.InfoLevel
+
+	if config.LogLevel != "" {
+		parsed, err := zerolog.ParseLevel(config.LogLevel)
+
+		if err != nil {
+			return err
+		}
+
+		level = parsed
+	}
+
+	var output io.Writer = os.Stdout
+
+	if config.Environment == "development" {
+		output = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	}
+
+	zerolog.TimeFieldFormat = time.RFC3339Nano
+	log.Logger = New(output).Level(level)
+
+	return nil
+}
+
+// creates a logger writing JSON events to w after redacting them
+func New(w io.Writer) zerolog.Logger {
+	return zerolog.New(NewRedactWriter(w)).With().Timestamp().Logger()
+}
+
+// the logger of the request, or the global logger outside of requests
+func FromContext(ctx context.Context) *zerolog.Logger {
+	if logger := zerolog.Ctx(ctx); logger.GetLevel() != zerolog.Disabled {
+		return logger
+	}
+	return &log.Logger
+}
+
+// stores a child of the global logger tagged with the request ID, and the trace ID when the request is traced, in the context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	logContext := log.Logger.With().Str(RequestIDKey, requestID)
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		logContext = logContext.Str(TraceIDKey, spanContext.TraceID().String())
+	}
+
+	logger := logContext.Logger()
+	return logger.WithContext(ctx)
+}
+
+// tags the logger of the request with the authenticated user, so later events, store calls included, carry it
+func SetUsername(ctx context.Context, username string) {
+	logger := zerolog.Ctx(ctx)
+
+	if logger.GetLevel() == zerolog.Disabled {
+		return
+	}
+
+	logger.UpdateContext(func(c zerolog.Context) zerolog.Context {
+		return c.Str(UsernameKey, username)
+	})
+}
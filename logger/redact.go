@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// field names whose values never reach the output; a field also matches when its name ends in _<name>,
+// e.g. hashed_password or refresh_token
+var sensitiveFields = []string{"password", "token", "secret", "email", "authorization", "totp_code"}
+
+// rewrites JSON log events, replacing the values of sensitive fields at any depth
+type RedactWriter struct {
+	out io.Writer
+}
+
+func NewRedactWriter(out io.Writer) *RedactWriter {
+	return &RedactWriter{out: out}
+}
+
+// zerolog writes one event per call
+func (w *RedactWriter) Write(p []byte) (int, error) {
+	if !mayBeSensitive(p) {
+		return w.out.Write(p)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+
+	var event map[string]any
+
+	if err := decoder.Decode(&event); err != nil {
+		// not an event we can parse; pass it through rather than lose it
+		return w.out.Write(p)
+	}
+
+	redact(event)
+
+	line, err := json.Marshal(event)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := w.out.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// cheap check that skips decoding events without any sensitive field name
+func mayBeSensitive(p []byte) bool {
+	lower := bytes.ToLower(p)
+
+	for _, field := range sensitiveFields {
+		if bytes.Contains(lower, []byte(field)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func redact(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSensitive(key) {
+				v[key] = redacted
+				continue
+			}
+			redact(field)
+		}
+	case []any:
+		for _, item := range v {
+			redact(item)
+		}
+	}
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, field := range sensitiveFields {
+		if key == field || strings.HasSuffix(key, "_"+field) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactWriter(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out)
+
+	logger.Info().
+		Str("password", "Secret123").
+		Str("access_token", "v2.local.abc").
+		Str("email", "user@example.com").
+		Str("username", "alice").
+		Interface("request", map[string]any{"refresh_token": "v2.local.def", "amount": 10}).
+		Msg("login")
+
+	require.NotContains(t, out.String(), "Secret123")
+	require.NotContains(t, out.String(), "v2.local")
+	require.NotContains(t, out.String(), "user@example.com")
+
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &event))
+	require.Equal(t, redacted, event["password"])
+	require.Equal(t, redacted, event["email"])
+	require.Equal(t, "alice", event["username"])
+	require.Equal(t, "login", event["message"])
+
+	request := event["request"].(map[string]any)
+	require.Equal(t, redacted, request["refresh_token"])
+	require.Equal(t, float64(10), request["amount"])
+}
+
+func TestRedactWriterPassesThrough(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out)
+
+	logger.Info().Int64("account_id", 1).Msg("transfer")
+
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &event))
+	require.Equal(t, float64(1), event["account_id"])
+
+	// events that are not JSON are written unchanged
+	_, err := NewRedactWriter(&out).Write([]byte("token: not json\n"))
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "token: not json")
+}
+
+func TestIsSensitive(t *testing.T) {
+	for _, key := range []string{"password", "hashed_password", "refresh_token", "TOTP_CODE", "totp_secret", "Authorization"} {
+		require.True(t, isSensitive(key), key)
+	}
+
+	for _, key := range []string{"username", "request_id", "tokens", "amount"} {
+		require.False(t, isSensitive(key), key)
+	}
+}
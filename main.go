@@ -1,41 +1,412 @@
 package main
 
 import (
-	"database/sql"
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mateusribs/simple_bank/api"
+	"github.com/mateusribs/simple_bank/cli"
+	"github.com/mateusribs/simple_bank/db/migration"
 	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/gapi"
+	"github.com/mateusribs/simple_bank/logger"
+	"github.com/mateusribs/simple_bank/metrics"
+	"github.com/mateusribs/simple_bank/token"
+	"github.com/mateusribs/simple_bank/tracing"
 	"github.com/mateusribs/simple_bank/util"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/pflag"
 )
 
 func main() {
-	config, err := util.LoadConfig(".")
+	// the flags before the command override the settings; parsing stops at the first argument that is not a flag
+	flags := pflag.NewFlagSet("simplebank", pflag.ContinueOnError)
+	flags.SetInterspersed(false)
+	configFile := flags.String("config", "", "read the settings from this .env, .yaml or .toml file instead of app.env")
+	util.RegisterConfigFlags(flags)
+
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return
+		}
+		os.Exit(2)
+	}
+
+	config, err := util.LoadConfigFrom(util.ConfigSource{Path: ".", File: *configFile, Flags: flags})
+
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot load config")
+	}
+
+	if err := logger.Setup(config); err != nil {
+		log.Fatal().Err(err).Msg("cannot set up logger")
+	}
+
+	// with arguments the binary runs a maintenance command instead of the servers
+	if args := flags.Args(); len(args) > 0 {
+		err := runCommand(config, args)
+
+		if errors.Is(err, cli.ErrUsage) {
+			os.Exit(2)
+		}
+
+		if err != nil {
+			log.Fatal().Err(err).Msg("command failed")
+		}
+		return
+	}
+
+	if err := config.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid config")
+	}
+
+	// cancelled by the first SIGINT or SIGTERM; a second one kills the process right away
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), config)
+
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot set up tracing")
+	}
+
+	store, closeStore, err := openStore(ctx, config)
+
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot open store")
+	}
+
+	// the jobs finish the batch they are running once ctx is cancelled
+	var jobs sync.WaitGroup
+
+	if config.HoldExpiryInterval > 0 {
+		jobs.Add(1)
+
+		go func() {
+			defer jobs.Done()
+			runHoldExpiry(ctx, store, config.HoldExpiryInterval)
+		}()
+	}
+
+	// requests are validated against the catalogue, so the service must not start with only the seeded currencies
+	currencies := db.NewCurrencyCatalog()
+
+	if err := currencies.Refresh(ctx, store); err != nil {
+		log.Fatal().Err(err).Msg("cannot load currencies")
+	}
+
+	if config.CurrencyRefreshInterval > 0 {
+		jobs.Add(1)
+
+		go func() {
+			defer jobs.Done()
+			currencies.Run(ctx, store, config.CurrencyRefreshInterval)
+		}()
+	}
+
+	if config.InterestJobInterval > 0 {
+		jobs.Add(1)
+
+		go func() {
+			defer jobs.Done()
+			runInterestJob(ctx, store, config.InterestJobInterval)
+		}()
+	}
+
+	tokenMaker, err := token.NewMaker(config.TokenMaker, config.TokenSymmetricKey)
+
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create token maker")
+	}
+
+	server, err := api.NewServer(config, store, tokenMaker, currencies)
+
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create server")
+	}
+
+	grpcServer, err := gapi.NewServer(config, store, tokenMaker, currencies)
+
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create gRPC server")
+	}
+
+	// the servers return nil once shut down, so any error received is a failure
+	errs := make(chan error, 3)
+
+	go func() {
+		if err := server.Start(config.ServerAddress); err != nil {
+			errs <- fmt.Errorf("HTTP server: %w", err)
+		}
+	}()
+
+	go func() {
+		if err := grpcServer.Start(config.GRPCServerAddress); err != nil {
+			errs <- fmt.Errorf("gRPC server: %w", err)
+		}
+	}()
+
+	// metrics are served on their own port, so they can be scraped without exposing them publicly
+	var metricsServer *http.Server
+
+	if config.MetricsServerAddress != "" {
+		adminMux := http.NewServeMux()
+		adminMux.Handle("/metrics", metrics.Handler())
+
+		metricsServer = &http.Server{
+			Addr: config.MetricsServerAddress,
+			Handler: adminMux,
+			ReadHeaderTimeout: config.HTTPReadHeaderTimeout,
+		}
+
+		go func() {
+			if err := metricsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("metrics server: %w", err)
+			}
+		}()
+	}
+
+	var serverErr error
+
+	select {
+	case serverErr = <-errs:
+		log.Error().Err(serverErr).Msg("server stopped, shutting down")
+	case <-ctx.Done():
+		log.Info().Msg("received shutdown signal")
+	}
+
+	stop()
+
+	// fail the readiness probe and keep serving while the load balancer takes the instance out of rotation
+	server.StartDraining()
+
+	if serverErr == nil && config.ShutdownDrainPeriod > 0 {
+		log.Info().Dur("drain_period", config.ShutdownDrainPeriod).Msg("draining")
+		time.Sleep(config.ShutdownDrainPeriod)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	// stop taking requests and let the running ones, such as transfers, commit
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("cannot shut down HTTP server")
+	}
+
+	if err := grpcServer.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("cannot shut down gRPC server")
+	}
+
+	// wait for the background jobs, which may be in the middle of a transaction
+	jobsDone := make(chan struct{})
+
+	go func() {
+		jobs.Wait()
+		close(jobsDone)
+	}()
+
+	select {
+	case <-jobsDone:
+	case <-shutdownCtx.Done():
+		log.Error().Msg("background jobs did not finish in time")
+	}
+
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("cannot shut down metrics server")
+		}
+	}
+
+	// nothing uses the pools anymore
+	closeStore()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("cannot flush traces")
+	}
+
+	if serverErr != nil {
+		log.Fatal().Err(serverErr).Msg("cannot start server")
+	}
+
+	log.Info().Msg("shutdown complete")
+}
+
+// the store of DB_SOURCE and the func that closes it: the Postgres pools, migrated when RUN_MIGRATIONS_ON_START
+// is set and exposed as metrics, or a MemStore when DB_SOURCE is memory
+func openStore(ctx context.Context, config util.Config) (db.Store, func(), error) {
+	if config.DBSource == util.DBSourceMemory {
+		log.Warn().Msg("DB_SOURCE is memory, the data is lost when the process exits")
+		return db.NewMemStore(), func() {}, nil
+	}
+
+	// waits for the database, so the service never reports ready without it
+	conn, err := db.Open(ctx, config)
 
 	if err != nil {
-		log.Fatal("cannot load config:", err)
+		return nil, nil, fmt.Errorf("cannot connect to db: %w", err)
 	}
 
-	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	replica, err := db.OpenReplica(ctx, config)
 
 	if err != nil {
-		log.Fatal("cannot connect to db:", err)
+		conn.Close()
+		return nil, nil, fmt.Errorf("cannot connect to db replica: %w", err)
+	}
+
+	closePools := func() {
+		conn.Close()
+
+		if replica != nil {
+			replica.Close()
+		}
+	}
+
+	if err := setUpPools(config, conn, replica); err != nil {
+		closePools()
+		return nil, nil, err
+	}
+
+	return db.NewStore(conn, db.StoreOptions{QueryTimeout: config.DBQueryTimeout, Replica: replica}), closePools, nil
+}
+
+func setUpPools(config util.Config, conn *pgxpool.Pool, replica *pgxpool.Pool) error {
+	if config.RunMigrationsOnStart {
+		if err := migration.Up(config.DBSource); err != nil {
+			return fmt.Errorf("cannot run migrations: %w", err)
+		}
 	}
 
-	store := db.NewStore(conn)
+	if err := metrics.RegisterDB(conn); err != nil {
+		return fmt.Errorf("cannot register db metrics: %w", err)
+	}
+
+	if replica != nil {
+		if err := metrics.RegisterDBReplica(replica); err != nil {
+			return fmt.Errorf("cannot register db replica metrics: %w", err)
+		}
+	}
 
-	server, err := api.NewServer(config, store)
+	return nil
+}
+
+func runCommand(config util.Config, args []string) error {
+	if args[0] == "config" {
+		return runConfig(config, args[1:])
+	}
+
+	// every other command would start from an empty store and lose its changes on exit
+	if config.DBSource == util.DBSourceMemory {
+		return fmt.Errorf("%s needs a database, DB_SOURCE is %s", args[0], util.DBSourceMemory)
+	}
+
+	if args[0] == "migrate" {
+		return runMigrate(config, args[1:])
+	}
+
+	conn, err := db.Open(context.Background(), config)
 
 	if err != nil {
-		log.Fatal("cannot create server:", err)
+		return fmt.Errorf("cannot connect to db: %w", err)
 	}
 
-	err = server.Start(config.ServerAddress)
+	defer conn.Close()
+
+	admin, err := cli.New(config, db.NewStore(conn, db.StoreOptions{QueryTimeout: config.DBQueryTimeout}), os.Stdin, os.Stdout, os.Stderr)
 
 	if err != nil {
-		log.Fatal("cannot start server:", err)
+		return err
+	}
+
+	return admin.Run(context.Background(), args)
+}
+
+const holdExpiryBatchSize = 100
+
+// periodically releases the holds that expired without being captured or voided, until ctx is cancelled
+func runHoldExpiry(ctx context.Context, store db.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for waitTick(ctx, ticker) {
+		for ctx.Err() == nil {
+			released, err := store.ExpireHoldsTx(context.Background(), holdExpiryBatchSize)
+
+			if err != nil {
+				log.Error().Err(err).Msg("cannot expire holds")
+				break
+			}
+
+			if released < holdExpiryBatchSize {
+				break
+			}
+		}
 	}
+}
+
+// accrues interest for every complete day since the last accrual and posts what was accrued in past months.
+// Both steps skip work already done, so the job can run more often than daily, and days missed while the
+// service was down are caught up on the next run.
+func runInterestJob(ctx context.Context, store db.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for waitTick(ctx, ticker) {
+		today := db.AccrualDate(time.Now())
 
+		if err := accrueMissedInterest(context.Background(), store, today); err != nil {
+			log.Error().Err(err).Msg("cannot accrue interest")
+			continue
+		}
 
-}
\ No newline at end of file
+		monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+		if _, err := store.PostInterest(context.Background(), monthStart); err != nil {
+			log.Error().Err(err).Msg("cannot post interest")
+		}
+	}
+}
+
+// accrues each day from the last accrued one through the day before today, or only the day before today
+// when nothing was accrued yet. The last day is accrued again in case a failure stopped it part way;
+// accruals are keyed by date, so the accounts it already covered are skipped.
+func accrueMissedInterest(ctx context.Context, store db.Store, today time.Time) error {
+	yesterday := today.AddDate(0, 0, -1)
+
+	last, err := store.GetLastInterestAccrualDate(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	from := db.AccrualDate(last)
+
+	if last.IsZero() || from.After(yesterday) {
+		from = yesterday
+	}
+
+	for date := from; !date.After(yesterday); date = date.AddDate(0, 0, 1) {
+		if _, err := store.AccrueInterest(ctx, date); err != nil {
+			return fmt.Errorf("cannot accrue interest of %s: %w", date.Format(time.DateOnly), err)
+		}
+	}
+
+	return nil
+}
+
+// waits for the next tick, reporting false once ctx is cancelled
+func waitTick(ctx context.Context, ticker *time.Ticker) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-ticker.C:
+		return true
+	}
+}
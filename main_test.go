@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mateusribs/simple_bank/cli"
+	mockdb "github.com/mateusribs/simple_bank/db/mock"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAccrueMissedInterest(t *testing.T) {
+	today := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+	day := func(offset int) time.Time { return today.AddDate(0, 0, offset) }
+
+	testCases := []struct{
+		name string
+		last time.Time
+		accrued []time.Time
+		failOn time.Time
+	}{
+		{
+			name: "UpToDate",
+			last: day(-1),
+			accrued: []time.Time{day(-1)},
+		},
+		{
+			// the service was down for three days, so the job picks up where it stopped
+			name: "Gap",
+			last: day(-4),
+			accrued: []time.Time{day(-4), day(-3), day(-2), day(-1)},
+		},
+		{
+			name: "NothingAccruedYet",
+			accrued: []time.Time{day(-1)},
+		},
+		{
+			name: "StopsAtFailure",
+			last: day(-4),
+			accrued: []time.Time{day(-4), day(-3)},
+			failOn: day(-3),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetLastInterestAccrualDate(gomock.Any()).Times(1).Return(tc.last, nil)
+
+			calls := []interface{}{}
+
+			for _, date := range tc.accrued {
+				var err error
+
+				if date.Equal(tc.failOn) {
+					err = errors.New("connection reset")
+				}
+
+				calls = append(calls, store.EXPECT().AccrueInterest(gomock.Any(), gomock.Eq(date)).Times(1).Return(0, err))
+			}
+
+			gomock.InOrder(calls...)
+
+			err := accrueMissedInterest(context.Background(), store, today)
+
+			if tc.failOn.IsZero() {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, "cannot accrue interest of 2024-03-07")
+			}
+		})
+	}
+}
+
+func TestRunConfigPrint(t *testing.T) {
+	config := util.Config{TokenSymmetricKey: util.RandomString(32), ServerAddress: "0.0.0.0:8080"}
+
+	// secrets stay masked unless asked for, so a pasted output does not leak them
+	output := captureStdout(t, func() {
+		require.NoError(t, runConfig(config, []string{"print"}))
+	})
+	require.Contains(t, output, "TOKEN_SYMMETRIC_KEY=REDACTED\n")
+	require.Contains(t, output, "SERVER_ADDRESS=0.0.0.0:8080\n")
+	require.NotContains(t, output, config.TokenSymmetricKey)
+
+	output = captureStdout(t, func() {
+		require.NoError(t, runConfig(config, []string{"print", "--show-secrets"}))
+	})
+	require.Contains(t, output, "TOKEN_SYMMETRIC_KEY="+config.TokenSymmetricKey+"\n")
+
+	output = captureStdout(t, func() {
+		require.NoError(t, runConfig(config, []string{"print", "--redacted"}))
+	})
+	require.Contains(t, output, "TOKEN_SYMMETRIC_KEY=REDACTED\n")
+
+	require.ErrorIs(t, runConfig(config, []string{"print", "--redacted", "--show-secrets"}), cli.ErrUsage)
+	require.ErrorIs(t, runConfig(config, []string{"print", "--redacted=false"}), cli.ErrUsage)
+}
+
+func TestOpenMemoryStore(t *testing.T) {
+	config := util.Config{DBSource: util.DBSourceMemory}
+
+	store, closeStore, err := openStore(context.Background(), config)
+	require.NoError(t, err)
+	t.Cleanup(closeStore)
+
+	require.IsType(t, &db.MemStore{}, store)
+	require.NoError(t, store.Ping(context.Background()))
+
+	// a command would lose its changes with the process, so it needs a database
+	require.ErrorContains(t, runCommand(config, []string{"user", "create"}), "user needs a database")
+	require.ErrorContains(t, runCommand(config, []string{"migrate", "up"}), "migrate needs a database")
+}
+
+func captureStdout(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = w, w
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	f()
+	require.NoError(t, w.Close())
+
+	output, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return string(output)
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "simple_bank"
+
+var (
+	// latency of HTTP requests by route template, so /accounts/1 and /accounts/2 share a series
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name: "request_duration_seconds",
+		Help: "Duration of HTTP requests by method, route and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	TransferTxDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name: "transfer_tx_duration_seconds",
+		Help: "Duration of transfer transactions by outcome.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"outcome"})
+
+	TransferTxFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name: "transfer_tx_failures_total",
+		Help: "Transfer transactions that did not commit, by reason.",
+	}, []string{"reason"})
+
+	// incremented by execTx each time it runs a transaction again after a transient failure
+	TxRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name: "tx_retries_total",
+		Help: "Database transactions retried after a serialization failure or deadlock.",
+	})
+
+	Transfers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name: "transfers_total",
+		Help: "Committed transfers by currency.",
+	}, []string{"currency"})
+
+	// amounts in minor units, summed per currency
+	TransferredAmount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name: "transferred_amount_total",
+		Help: "Sum of committed transfer amounts in minor units, by currency.",
+	}, []string{"currency"})
+
+	LoginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name: "login_failures_total",
+		Help: "Failed logins by protocol and reason.",
+	}, []string{"protocol", "reason"})
+)
+
+// login failure reasons
+const (
+	LoginUnknownUser = "unknown_user"
+	LoginWrongPassword = "wrong_password"
+)
+
+// serves the default registry, which holds the metrics above and the Go runtime and process collectors
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// exposes the pool statistics of the database, such as open, in-use and idle connections and wait time
+func RegisterDB(pool *pgxpool.Pool) error {
+	return prometheus.Register(newPoolCollector(pool, namespace))
+}
+
+// exposes the pool statistics of the read-only replica, labelled apart from the primary
+func RegisterDBReplica(pool *pgxpool.Pool) error {
+	return prometheus.Register(newPoolCollector(pool, namespace+"_replica"))
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// reports pgxpool statistics under the names the database/sql collector used, so dashboards kept working after the move to pgx;
+// pgxpool does not time waits for a free connection, so the total acquire time has a name of its own
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	maxOpen *prometheus.Desc
+	open *prometheus.Desc
+	inUse *prometheus.Desc
+	idle *prometheus.Desc
+	waitCount *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	maxIdleTimeClosed *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool, dbName string) *poolCollector {
+	labels := prometheus.Labels{"db_name": dbName}
+
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("go", "sql", name), help, nil, labels)
+	}
+
+	return &poolCollector{
+		pool: pool,
+		maxOpen: desc("max_open_connections", "Maximum number of open connections to the database."),
+		open: desc("open_connections", "The number of established connections both in use and idle."),
+		inUse: desc("in_use_connections", "The number of connections currently in use."),
+		idle: desc("idle_connections", "The number of idle connections."),
+		waitCount: desc("wait_count_total", "The total number of connections waited for."),
+		acquireDuration: desc("acquire_duration_seconds_total", "The total time spent acquiring connections, whether or not they had to be waited for."),
+		maxIdleTimeClosed: desc("max_idle_time_closed_total", "The total number of connections closed due to DB_CONN_MAX_IDLE_TIME."),
+		maxLifetimeClosed: desc("max_lifetime_closed_total", "The total number of connections closed due to DB_CONN_MAX_LIFETIME."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.acquireDuration
+	ch <- c.maxIdleTimeClosed
+	ch <- c.maxLifetimeClosed
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.maxIdleTimeClosed, prometheus.CounterValue, float64(stat.MaxIdleDestroyCount()))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stat.MaxLifetimeDestroyCount()))
+}
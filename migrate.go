@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/mateusribs/simple_bank/db/migration"
+	db "github.com/mateusribs/simple_bank/db/sqlc"
+	"github.com/mateusribs/simple_bank/util"
+	"github.com/rs/zerolog/log"
+)
+
+const migrateUsage = "usage: simplebank migrate up [N] | down N|all | version | force VERSION"
+
+// runs the migrations embedded in the binary against DB_SOURCE
+func runMigrate(config util.Config, args []string) (err error) {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	// start.sh migrates before the server starts, so this waits for the database the same way
+	conn, err := db.Open(context.Background(), config)
+
+	if err != nil {
+		return err
+	}
+
+	conn.Close()
+
+	m, err := migration.New(config.DBSource)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if sourceErr, dbErr := m.Close(); err == nil {
+			err = errors.Join(sourceErr, dbErr)
+		}
+	}()
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		err = m.Up()
+	case args[0] == "up" && len(args) == 2:
+		var steps int
+		steps, err = positiveInt(args[1])
+
+		if err == nil {
+			err = m.Steps(steps)
+		}
+	// rolling everything back drops every table, so it has to be asked for explicitly
+	case args[0] == "down" && len(args) == 2 && args[1] == "all":
+		err = m.Down()
+	case args[0] == "down" && len(args) == 2:
+		var steps int
+		steps, err = positiveInt(args[1])
+
+		if err == nil {
+			err = m.Steps(-steps)
+		}
+	case args[0] == "version" && len(args) == 1:
+		version, dirty, versionErr := m.Version()
+
+		if errors.Is(versionErr, migrate.ErrNilVersion) {
+			fmt.Println("no migration applied")
+			return nil
+		}
+
+		if versionErr != nil {
+			return versionErr
+		}
+
+		fmt.Printf("version %d, dirty %t, binary expects %d\n", version, dirty, migration.LatestVersion())
+		return nil
+	// marks a version as applied without running it, to recover from a migration that failed halfway
+	case args[0] == "force" && len(args) == 2:
+		var version int
+		version, err = strconv.Atoi(args[1])
+
+		if err == nil {
+			err = m.Force(version)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	if errors.Is(err, migrate.ErrNoChange) {
+		log.Info().Msg("no migration to apply")
+		return nil
+	}
+
+	return err
+}
+
+func positiveInt(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q is not a positive number of migrations", s)
+	}
+
+	return n, nil
+}
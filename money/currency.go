@@ -0,0 +1,193 @@
+package money
+
+import "strings"
+
+// ISO 4217 metadata of a currency
+type Currency struct {
+	Code string `json:"code"`
+	// number of digits after the decimal separator, amounts are stored in units of 10^-Exponent
+	Exponent int    `json:"exponent"`
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name"`
+}
+
+// returns the metadata of an ISO 4217 currency code
+func Lookup(code string) (Currency, bool) {
+	currency, ok := currencies[strings.ToUpper(code)]
+	return currency, ok
+}
+
+// returns the metadata of every known currency
+func Currencies() []Currency {
+	list := make([]Currency, 0, len(currencies))
+
+	for _, currency := range currencies {
+		list = append(list, currency)
+	}
+
+	return list
+}
+
+func currency(code string, exponent int, symbol string, name string) Currency {
+	return Currency{Code: code, Exponent: exponent, Symbol: symbol, Name: name}
+}
+
+// active ISO 4217 currencies, funds and precious metals excluded
+var currencies = map[string]Currency{
+	"AED": currency("AED", 2, "د.إ", "UAE Dirham"),
+	"AFN": currency("AFN", 2, "؋", "Afghani"),
+	"ALL": currency("ALL", 2, "L", "Lek"),
+	"AMD": currency("AMD", 2, "֏", "Armenian Dram"),
+	"ANG": currency("ANG", 2, "ƒ", "Netherlands Antillean Guilder"),
+	"AOA": currency("AOA", 2, "Kz", "Kwanza"),
+	"ARS": currency("ARS", 2, "$", "Argentine Peso"),
+	"AUD": currency("AUD", 2, "A$", "Australian Dollar"),
+	"AWG": currency("AWG", 2, "ƒ", "Aruban Florin"),
+	"AZN": currency("AZN", 2, "₼", "Azerbaijan Manat"),
+	"BAM": currency("BAM", 2, "KM", "Convertible Mark"),
+	"BBD": currency("BBD", 2, "$", "Barbados Dollar"),
+	"BDT": currency("BDT", 2, "৳", "Taka"),
+	"BGN": currency("BGN", 2, "лв", "Bulgarian Lev"),
+	"BHD": currency("BHD", 3, ".د.ب", "Bahraini Dinar"),
+	"BIF": currency("BIF", 0, "FBu", "Burundi Franc"),
+	"BMD": currency("BMD", 2, "$", "Bermudian Dollar"),
+	"BND": currency("BND", 2, "$", "Brunei Dollar"),
+	"BOB": currency("BOB", 2, "Bs", "Boliviano"),
+	"BRL": currency("BRL", 2, "R$", "Brazilian Real"),
+	"BSD": currency("BSD", 2, "$", "Bahamian Dollar"),
+	"BTN": currency("BTN", 2, "Nu.", "Ngultrum"),
+	"BWP": currency("BWP", 2, "P", "Pula"),
+	"BYN": currency("BYN", 2, "Br", "Belarusian Ruble"),
+	"BZD": currency("BZD", 2, "$", "Belize Dollar"),
+	"CAD": currency("CAD", 2, "CA$", "Canadian Dollar"),
+	"CDF": currency("CDF", 2, "FC", "Congolese Franc"),
+	"CHF": currency("CHF", 2, "CHF", "Swiss Franc"),
+	"CLP": currency("CLP", 0, "$", "Chilean Peso"),
+	"CNY": currency("CNY", 2, "¥", "Yuan Renminbi"),
+	"COP": currency("COP", 2, "$", "Colombian Peso"),
+	"CRC": currency("CRC", 2, "₡", "Costa Rican Colon"),
+	"CUP": currency("CUP", 2, "$", "Cuban Peso"),
+	"CVE": currency("CVE", 2, "$", "Cabo Verde Escudo"),
+	"CZK": currency("CZK", 2, "Kč", "Czech Koruna"),
+	"DJF": currency("DJF", 0, "Fdj", "Djibouti Franc"),
+	"DKK": currency("DKK", 2, "kr", "Danish Krone"),
+	"DOP": currency("DOP", 2, "$", "Dominican Peso"),
+	"DZD": currency("DZD", 2, "د.ج", "Algerian Dinar"),
+	"EGP": currency("EGP", 2, "E£", "Egyptian Pound"),
+	"ERN": currency("ERN", 2, "Nfk", "Nakfa"),
+	"ETB": currency("ETB", 2, "Br", "Ethiopian Birr"),
+	"EUR": currency("EUR", 2, "€", "Euro"),
+	"FJD": currency("FJD", 2, "$", "Fiji Dollar"),
+	"FKP": currency("FKP", 2, "£", "Falkland Islands Pound"),
+	"GBP": currency("GBP", 2, "£", "Pound Sterling"),
+	"GEL": currency("GEL", 2, "₾", "Lari"),
+	"GHS": currency("GHS", 2, "GH₵", "Ghana Cedi"),
+	"GIP": currency("GIP", 2, "£", "Gibraltar Pound"),
+	"GMD": currency("GMD", 2, "D", "Dalasi"),
+	"GNF": currency("GNF", 0, "FG", "Guinean Franc"),
+	"GTQ": currency("GTQ", 2, "Q", "Quetzal"),
+	"GYD": currency("GYD", 2, "$", "Guyana Dollar"),
+	"HKD": currency("HKD", 2, "HK$", "Hong Kong Dollar"),
+	"HNL": currency("HNL", 2, "L", "Lempira"),
+	"HTG": currency("HTG", 2, "G", "Gourde"),
+	"HUF": currency("HUF", 2, "Ft", "Forint"),
+	"IDR": currency("IDR", 2, "Rp", "Rupiah"),
+	"ILS": currency("ILS", 2, "₪", "New Israeli Sheqel"),
+	"INR": currency("INR", 2, "₹", "Indian Rupee"),
+	"IQD": currency("IQD", 3, "ع.د", "Iraqi Dinar"),
+	"IRR": currency("IRR", 2, "﷼", "Iranian Rial"),
+	"ISK": currency("ISK", 0, "kr", "Iceland Krona"),
+	"JMD": currency("JMD", 2, "$", "Jamaican Dollar"),
+	"JOD": currency("JOD", 3, "د.ا", "Jordanian Dinar"),
+	"JPY": currency("JPY", 0, "¥", "Yen"),
+	"KES": currency("KES", 2, "KSh", "Kenyan Shilling"),
+	"KGS": currency("KGS", 2, "сом", "Som"),
+	"KHR": currency("KHR", 2, "៛", "Riel"),
+	"KMF": currency("KMF", 0, "CF", "Comorian Franc"),
+	"KPW": currency("KPW", 2, "₩", "North Korean Won"),
+	"KRW": currency("KRW", 0, "₩", "Won"),
+	"KWD": currency("KWD", 3, "د.ك", "Kuwaiti Dinar"),
+	"KYD": currency("KYD", 2, "$", "Cayman Islands Dollar"),
+	"KZT": currency("KZT", 2, "₸", "Tenge"),
+	"LAK": currency("LAK", 2, "₭", "Lao Kip"),
+	"LBP": currency("LBP", 2, "ل.ل", "Lebanese Pound"),
+	"LKR": currency("LKR", 2, "Rs", "Sri Lanka Rupee"),
+	"LRD": currency("LRD", 2, "$", "Liberian Dollar"),
+	"LSL": currency("LSL", 2, "L", "Loti"),
+	"LYD": currency("LYD", 3, "ل.د", "Libyan Dinar"),
+	"MAD": currency("MAD", 2, "د.م.", "Moroccan Dirham"),
+	"MDL": currency("MDL", 2, "L", "Moldovan Leu"),
+	"MGA": currency("MGA", 2, "Ar", "Malagasy Ariary"),
+	"MKD": currency("MKD", 2, "ден", "Denar"),
+	"MMK": currency("MMK", 2, "K", "Kyat"),
+	"MNT": currency("MNT", 2, "₮", "Tugrik"),
+	"MOP": currency("MOP", 2, "MOP$", "Pataca"),
+	"MRU": currency("MRU", 2, "UM", "Ouguiya"),
+	"MUR": currency("MUR", 2, "₨", "Mauritius Rupee"),
+	"MVR": currency("MVR", 2, "Rf", "Rufiyaa"),
+	"MWK": currency("MWK", 2, "MK", "Malawi Kwacha"),
+	"MXN": currency("MXN", 2, "MX$", "Mexican Peso"),
+	"MYR": currency("MYR", 2, "RM", "Malaysian Ringgit"),
+	"MZN": currency("MZN", 2, "MT", "Mozambique Metical"),
+	"NAD": currency("NAD", 2, "$", "Namibia Dollar"),
+	"NGN": currency("NGN", 2, "₦", "Naira"),
+	"NIO": currency("NIO", 2, "C$", "Cordoba Oro"),
+	"NOK": currency("NOK", 2, "kr", "Norwegian Krone"),
+	"NPR": currency("NPR", 2, "₨", "Nepalese Rupee"),
+	"NZD": currency("NZD", 2, "NZ$", "New Zealand Dollar"),
+	"OMR": currency("OMR", 3, "ر.ع.", "Rial Omani"),
+	"PAB": currency("PAB", 2, "B/.", "Balboa"),
+	"PEN": currency("PEN", 2, "S/", "Sol"),
+	"PGK": currency("PGK", 2, "K", "Kina"),
+	"PHP": currency("PHP", 2, "₱", "Philippine Peso"),
+	"PKR": currency("PKR", 2, "₨", "Pakistan Rupee"),
+	"PLN": currency("PLN", 2, "zł", "Zloty"),
+	"PYG": currency("PYG", 0, "₲", "Guarani"),
+	"QAR": currency("QAR", 2, "ر.ق", "Qatari Rial"),
+	"RON": currency("RON", 2, "lei", "Romanian Leu"),
+	"RSD": currency("RSD", 2, "дин.", "Serbian Dinar"),
+	"RUB": currency("RUB", 2, "₽", "Russian Ruble"),
+	"RWF": currency("RWF", 0, "FRw", "Rwanda Franc"),
+	"SAR": currency("SAR", 2, "ر.س", "Saudi Riyal"),
+	"SBD": currency("SBD", 2, "$", "Solomon Islands Dollar"),
+	"SCR": currency("SCR", 2, "₨", "Seychelles Rupee"),
+	"SDG": currency("SDG", 2, "ج.س.", "Sudanese Pound"),
+	"SEK": currency("SEK", 2, "kr", "Swedish Krona"),
+	"SGD": currency("SGD", 2, "S$", "Singapore Dollar"),
+	"SHP": currency("SHP", 2, "£", "Saint Helena Pound"),
+	"SLE": currency("SLE", 2, "Le", "Leone"),
+	"SOS": currency("SOS", 2, "Sh", "Somali Shilling"),
+	"SRD": currency("SRD", 2, "$", "Surinam Dollar"),
+	"SSP": currency("SSP", 2, "£", "South Sudanese Pound"),
+	"STN": currency("STN", 2, "Db", "Dobra"),
+	"SVC": currency("SVC", 2, "₡", "El Salvador Colon"),
+	"SYP": currency("SYP", 2, "£", "Syrian Pound"),
+	"SZL": currency("SZL", 2, "E", "Lilangeni"),
+	"THB": currency("THB", 2, "฿", "Baht"),
+	"TJS": currency("TJS", 2, "SM", "Somoni"),
+	"TMT": currency("TMT", 2, "m", "Turkmenistan New Manat"),
+	"TND": currency("TND", 3, "د.ت", "Tunisian Dinar"),
+	"TOP": currency("TOP", 2, "T$", "Pa’anga"),
+	"TRY": currency("TRY", 2, "₺", "Turkish Lira"),
+	"TTD": currency("TTD", 2, "$", "Trinidad and Tobago Dollar"),
+	"TWD": currency("TWD", 2, "NT$", "New Taiwan Dollar"),
+	"TZS": currency("TZS", 2, "TSh", "Tanzanian Shilling"),
+	"UAH": currency("UAH", 2, "₴", "Hryvnia"),
+	"UGX": currency("UGX", 0, "USh", "Uganda Shilling"),
+	"USD": currency("USD", 2, "$", "US Dollar"),
+	"UYU": currency("UYU", 2, "$", "Peso Uruguayo"),
+	"UZS": currency("UZS", 2, "сўм", "Uzbekistan Sum"),
+	"VED": currency("VED", 2, "Bs.D", "Bolívar Soberano"),
+	"VES": currency("VES", 2, "Bs.S", "Bolívar Soberano"),
+	"VND": currency("VND", 0, "₫", "Dong"),
+	"VUV": currency("VUV", 0, "VT", "Vatu"),
+	"WST": currency("WST", 2, "WS$", "Tala"),
+	"XAF": currency("XAF", 0, "FCFA", "CFA Franc BEAC"),
+	"XCD": currency("XCD", 2, "EC$", "East Caribbean Dollar"),
+	"XOF": currency("XOF", 0, "CFA", "CFA Franc BCEAO"),
+	"XPF": currency("XPF", 0, "CFPF", "CFP Franc"),
+	"YER": currency("YER", 2, "﷼", "Yemeni Rial"),
+	"ZAR": currency("ZAR", 2, "R", "Rand"),
+	"ZMW": currency("ZMW", 2, "ZK", "Zambian Kwacha"),
+	"ZWL": currency("ZWL", 2, "$", "Zimbabwe Dollar"),
+}
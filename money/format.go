@@ -0,0 +1,139 @@
+package money
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// keep the amount and its symbol on the same line
+const (
+	nbsp       = "\u00a0"
+	narrowNbsp = "\u202f"
+)
+
+// how a locale writes amounts of money
+type format struct {
+	group   string
+	decimal string
+	// the symbol goes after the number, separated by a space
+	symbolAfter bool
+	// a space between a leading symbol and the number
+	symbolSpace bool
+}
+
+var (
+	formatEnglish = format{group: ",", decimal: "."}
+
+	formats = map[language.Tag]format{
+		language.English:            formatEnglish,
+		language.Japanese:           formatEnglish,
+		language.Chinese:            formatEnglish,
+		language.Korean:             formatEnglish,
+		language.German:             {group: ".", decimal: ",", symbolAfter: true},
+		language.Spanish:            {group: ".", decimal: ",", symbolAfter: true},
+		language.Italian:            {group: ".", decimal: ",", symbolAfter: true},
+		language.Portuguese:         {group: ".", decimal: ",", symbolSpace: true},
+		language.EuropeanPortuguese: {group: nbsp, decimal: ",", symbolAfter: true},
+		language.Dutch:              {group: ".", decimal: ",", symbolSpace: true},
+		language.French:             {group: narrowNbsp, decimal: ",", symbolAfter: true},
+		language.Polish:             {group: nbsp, decimal: ",", symbolAfter: true},
+		language.Swedish:            {group: nbsp, decimal: ",", symbolAfter: true},
+		language.Russian:            {group: nbsp, decimal: ",", symbolAfter: true},
+	}
+
+	formatTags    []language.Tag
+	formatMatcher language.Matcher
+)
+
+func init() {
+	// the first tag is the fallback of unknown locales
+	formatTags = append(formatTags, language.English)
+
+	for tag := range formats {
+		if tag != language.English {
+			formatTags = append(formatTags, tag)
+		}
+	}
+
+	formatMatcher = language.NewMatcher(formatTags)
+}
+
+// formats the amount for a BCP 47 locale such as "en-US", "pt-BR" or "de", or an Accept-Language header;
+// unknown locales fall back to English
+func (m Money) Format(locale string) string {
+	currency, ok := Lookup(m.Currency)
+
+	if !ok {
+		return m.String()
+	}
+
+	f := localeFormat(locale)
+
+	decimal := m.Decimal()
+	negative := strings.HasPrefix(decimal, "-")
+	major, minor, _ := strings.Cut(strings.TrimPrefix(decimal, "-"), ".")
+
+	number := groupDigits(major, f.group)
+
+	if minor != "" {
+		number += f.decimal + minor
+	}
+
+	var formatted string
+
+	switch {
+	case f.symbolAfter:
+		formatted = number + nbsp + currency.Symbol
+	case f.symbolSpace:
+		formatted = currency.Symbol + nbsp + number
+	default:
+		formatted = currency.Symbol + number
+	}
+
+	if negative {
+		return "-" + formatted
+	}
+
+	return formatted
+}
+
+func localeFormat(locale string) format {
+	tags, _, err := language.ParseAcceptLanguage(locale)
+
+	if err != nil || len(tags) == 0 {
+		return formatEnglish
+	}
+
+	_, index, confidence := formatMatcher.Match(tags...)
+
+	if confidence == language.No {
+		return formatEnglish
+	}
+
+	return formats[formatTags[index]]
+}
+
+// inserts the group separator every three digits from the right
+func groupDigits(digits string, separator string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var builder strings.Builder
+
+	head := len(digits) % 3
+
+	if head > 0 {
+		builder.WriteString(digits[:head])
+	}
+
+	for i := head; i < len(digits); i += 3 {
+		if builder.Len() > 0 {
+			builder.WriteString(separator)
+		}
+		builder.WriteString(digits[i : i+3])
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,181 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount overflows")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// an amount in the minor unit of its currency, cents for USD and yen for JPY
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// creates money of a known ISO 4217 currency
+func New(amount int64, code string) (Money, error) {
+	currency, ok := Lookup(code)
+
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, code)
+	}
+
+	return Money{Amount: amount, Currency: currency.Code}, nil
+}
+
+// parses a decimal amount in major units, such as "12.50" for USD; more decimals than the currency has are rejected
+func Parse(value string, code string) (Money, error) {
+	currency, ok := Lookup(code)
+
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, code)
+	}
+
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	major, minor, _ := strings.Cut(value, ".")
+
+	if major == "" || len(minor) > currency.Exponent || strings.ContainsAny(major+minor, "+-") {
+		return Money{}, fmt.Errorf("%w: %q for %s", ErrInvalidAmount, value, currency.Code)
+	}
+
+	minor += strings.Repeat("0", currency.Exponent-len(minor))
+
+	amount, err := strconv.ParseInt(major+minor, 10, 64)
+
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return Money{}, ErrOverflow
+		}
+		return Money{}, fmt.Errorf("%w: %q for %s", ErrInvalidAmount, value, currency.Code)
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return Money{Amount: amount, Currency: currency.Code}, nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, ErrOverflow
+	}
+
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	negated, err := other.Neg()
+
+	if err != nil {
+		return Money{}, err
+	}
+
+	return m.Add(negated)
+}
+
+func (m Money) Neg() (Money, error) {
+	if m.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+
+	return Money{Amount: -m.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Mul(factor int64) (Money, error) {
+	if m.Amount == 0 || factor == 0 {
+		return Money{Currency: m.Currency}, nil
+	}
+
+	product := m.Amount * factor
+
+	if product/factor != m.Amount || (m.Amount == -1 && factor == math.MinInt64) || (factor == -1 && m.Amount == math.MinInt64) {
+		return Money{}, ErrOverflow
+	}
+
+	return Money{Amount: product, Currency: m.Currency}, nil
+}
+
+// returns -1, 0 or 1 as m is less than, equal to or greater than other
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// formats the amount as a plain decimal in major units, "-12.50" for -1250 USD
+func (m Money) Decimal() string {
+	currency, _ := Lookup(m.Currency)
+
+	digits := strconv.FormatUint(absAmount(m.Amount), 10)
+
+	if currency.Exponent > 0 {
+		if len(digits) <= currency.Exponent {
+			digits = strings.Repeat("0", currency.Exponent-len(digits)+1) + digits
+		}
+		split := len(digits) - currency.Exponent
+		digits = digits[:split] + "." + digits[split:]
+	}
+
+	if m.Amount < 0 {
+		return "-" + digits
+	}
+
+	return digits
+}
+
+// formats the amount with its ISO code, "USD 12.50"
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	return nil
+}
+
+// returns |amount| without overflowing on the minimum int64
+func absAmount(amount int64) uint64 {
+	if amount < 0 {
+		return uint64(-(amount + 1)) + 1
+	}
+
+	return uint64(amount)
+}
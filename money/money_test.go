@@ -0,0 +1,149 @@
+package money
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	usd, ok := Lookup("usd")
+	require.True(t, ok)
+	require.Equal(t, "USD", usd.Code)
+	require.Equal(t, 2, usd.Exponent)
+	require.Equal(t, "$", usd.Symbol)
+
+	jpy, ok := Lookup("JPY")
+	require.True(t, ok)
+	require.Equal(t, 0, jpy.Exponent)
+
+	kwd, ok := Lookup("KWD")
+	require.True(t, ok)
+	require.Equal(t, 3, kwd.Exponent)
+
+	_, ok = Lookup("XYZ")
+	require.False(t, ok)
+
+	require.NotEmpty(t, Currencies())
+}
+
+func TestNew(t *testing.T) {
+	m, err := New(1250, "brl")
+	require.NoError(t, err)
+	require.Equal(t, Money{Amount: 1250, Currency: "BRL"}, m)
+
+	_, err = New(1250, "XYZ")
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		value    string
+		currency string
+		amount   int64
+		err      error
+	}{
+		{value: "12.50", currency: "USD", amount: 1250},
+		{value: "12.5", currency: "USD", amount: 1250},
+		{value: "12", currency: "USD", amount: 1200},
+		{value: "-0.01", currency: "EUR", amount: -1},
+		{value: "1000", currency: "JPY", amount: 1000},
+		{value: "1.234", currency: "KWD", amount: 1234},
+		{value: "10.5", currency: "JPY", err: ErrInvalidAmount},
+		{value: "1.234", currency: "USD", err: ErrInvalidAmount},
+		{value: "abc", currency: "USD", err: ErrInvalidAmount},
+		{value: ".50", currency: "USD", err: ErrInvalidAmount},
+		{value: "--1", currency: "USD", err: ErrInvalidAmount},
+		{value: "99999999999999999999", currency: "USD", err: ErrOverflow},
+		{value: "1", currency: "XYZ", err: ErrUnknownCurrency},
+	}
+
+	for _, tc := range testCases {
+		m, err := Parse(tc.value, tc.currency)
+
+		if tc.err != nil {
+			require.ErrorIs(t, err, tc.err, tc.value)
+			continue
+		}
+
+		require.NoError(t, err, tc.value)
+		require.Equal(t, tc.amount, m.Amount, tc.value)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a := Money{Amount: 1000, Currency: "USD"}
+	b := Money{Amount: 250, Currency: "USD"}
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+	require.Equal(t, int64(1250), sum.Amount)
+
+	diff, err := b.Sub(a)
+	require.NoError(t, err)
+	require.Equal(t, int64(-750), diff.Amount)
+	require.True(t, diff.IsNegative())
+
+	product, err := b.Mul(3)
+	require.NoError(t, err)
+	require.Equal(t, int64(750), product.Amount)
+
+	cmp, err := a.Cmp(b)
+	require.NoError(t, err)
+	require.Equal(t, 1, cmp)
+
+	_, err = a.Add(Money{Amount: 1, Currency: "EUR"})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = a.Cmp(Money{Amount: 1, Currency: "EUR"})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	max := Money{Amount: math.MaxInt64, Currency: "USD"}
+	min := Money{Amount: math.MinInt64, Currency: "USD"}
+
+	_, err = max.Add(Money{Amount: 1, Currency: "USD"})
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = min.Sub(Money{Amount: 1, Currency: "USD"})
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = min.Neg()
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = max.Mul(2)
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = min.Mul(-1)
+	require.ErrorIs(t, err, ErrOverflow)
+
+	zero, err := max.Mul(0)
+	require.NoError(t, err)
+	require.True(t, zero.IsZero())
+}
+
+func TestFormat(t *testing.T) {
+	testCases := []struct {
+		money  Money
+		locale string
+		want   string
+	}{
+		{money: Money{Amount: 123456789, Currency: "USD"}, locale: "en-US", want: "$1,234,567.89"},
+		{money: Money{Amount: -5, Currency: "USD"}, locale: "en", want: "-$0.05"},
+		{money: Money{Amount: 123456, Currency: "EUR"}, locale: "de-DE", want: "1.234,56\u00a0€"},
+		{money: Money{Amount: 123456, Currency: "EUR"}, locale: "fr", want: "1\u202f234,56\u00a0€"},
+		{money: Money{Amount: 123456, Currency: "BRL"}, locale: "pt-BR", want: "R$\u00a01.234,56"},
+		{money: Money{Amount: 1234, Currency: "JPY"}, locale: "ja-JP", want: "¥1,234"},
+		{money: Money{Amount: 1234, Currency: "KWD"}, locale: "en", want: "د.ك1.234"},
+		{money: Money{Amount: 100, Currency: "USD"}, locale: "de-CH,de;q=0.9,en;q=0.8", want: "1,00\u00a0$"},
+		{money: Money{Amount: 100, Currency: "USD"}, locale: "xx", want: "$1.00"},
+		{money: Money{Amount: 100, Currency: "USD"}, locale: "", want: "$1.00"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, tc.money.Format(tc.locale), tc.locale)
+	}
+
+	require.Equal(t, "JPY 1234", Money{Amount: 1234, Currency: "JPY"}.String())
+	require.Equal(t, "USD -0.05", Money{Amount: -5, Currency: "USD"}.String())
+}
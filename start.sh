@@ -2,8 +2,11 @@
 
 set -e
 
-echo "run DB migration"
-/app/migrate -path /app/migration -database "$DB_SOURCE" --verbose up
+# an in-memory store has no schema to migrate
+if [ "$DB_SOURCE" != "memory" ]; then
+  echo "run DB migration"
+  /app/simplebank migrate up
+fi
 
 echo "start app"
 exec "$@"
\ No newline at end of file
@@ -28,6 +28,20 @@ func (maker *JWTMaker) CreateToken(username string, duration time.Duration) (str
 	return token, payload, err
 }
 
+func (maker *JWTMaker) CreateElevatedToken(username string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewElevatedPayload(username, duration)
+
+	if err != nil {
+		return "", payload, err
+	}
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+
+	token, err := jwtToken.SignedString([]byte(maker.secretKey))
+
+	return token, payload, err
+}
+
 func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
 	keyFunc := func(token *jwt.Token) (interface{}, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
@@ -65,4 +65,19 @@ func TestInvalidJWTTokenAlgNone(t *testing.T){
 	require.Error(t, err)
 	require.EqualError(t, err, ErrInvalidToken.Error())
 	require.Nil(t, payload)
-}
\ No newline at end of file
+}
+func TestElevatedJWTToken(t *testing.T){
+	maker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+
+	token, payload, err := maker.CreateElevatedToken(username, time.Minute)
+	require.NoError(t, err)
+	require.True(t, payload.Elevated)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.True(t, payload.Elevated)
+	require.Equal(t, username, payload.Username)
+}
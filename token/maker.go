@@ -1,12 +1,31 @@
 package token
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/mateusribs/simple_bank/util"
+)
 
 // manage tokens
 type Maker interface {
 	//creates new token for specific username and valid duration
 	CreateToken(username string, duration time.Duration) (string, *Payload, error)
 
+	// creates a short-lived token carrying the elevated claim, for users who just re-authenticated
+	CreateElevatedToken(username string, duration time.Duration) (string, *Payload, error)
+
 	// check if token is valid
 	VerifyToken(token string) (*Payload, error)
-}
\ No newline at end of file
+}
+// creates the maker selected by TOKEN_MAKER
+func NewMaker(kind string, key string) (Maker, error) {
+	switch kind {
+	case util.TokenMakerPaseto:
+		return NewPasetoMaker(key)
+	case util.TokenMakerJWT:
+		return NewJWTMaker(key)
+	default:
+		return nil, fmt.Errorf("unsupported token maker %q", kind)
+	}
+}
@@ -46,4 +46,27 @@ func TestExpiredPasetoToken(t *testing.T){
 	require.Error(t, err)
 	require.EqualError(t, err, ErrExpiredToken.Error())
 	require.Nil(t, payload)
-}
\ No newline at end of file
+}
+func TestElevatedPasetoToken(t *testing.T){
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+
+	token, payload, err := maker.CreateToken(username, time.Minute)
+	require.NoError(t, err)
+	require.False(t, payload.Elevated)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.False(t, payload.Elevated)
+
+	token, payload, err = maker.CreateElevatedToken(username, time.Minute)
+	require.NoError(t, err)
+	require.True(t, payload.Elevated)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.True(t, payload.Elevated)
+	require.Equal(t, username, payload.Username)
+}
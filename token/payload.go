@@ -19,6 +19,8 @@ type Payload struct {
 	Username  string    `json:"username"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
+	// set on short-lived tokens issued after the user re-authenticated
+	Elevated bool `json:"elevated,omitempty"`
 }
 
 // GetAudience implements jwt.Claims.
@@ -74,3 +76,16 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 
 	return payload, nil
 }
+
+// creates the payload of a token issued right after the user re-authenticated
+func NewElevatedPayload(username string, duration time.Duration) (*Payload, error) {
+	payload, err := NewPayload(username, duration)
+
+	if err != nil {
+		return nil, err
+	}
+
+	payload.Elevated = true
+
+	return payload, nil
+}
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mateusribs/simple_bank/util"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const ServiceName = "simple_bank"
+
+// exporters selectable with TRACING_EXPORTER
+const (
+	ExporterNone = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP = "otlp"
+)
+
+// name of the instrumentation library reported with every span
+const instrumentationName = "github.com/mateusribs/simple_bank"
+
+// tracer used by the packages of the service; a no-op until Setup installs a provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// installs the global tracer provider and W3C propagation; the returned function flushes pending spans.
+// With no exporter configured the global no-op provider is kept, so spans cost next to nothing.
+func Setup(ctx context.Context, config util.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch config.TracingExporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		options := []otlptracegrpc.Option{}
+
+		if config.TracingOTLPEndpoint != "" {
+			options = append(options, otlptracegrpc.WithEndpoint(config.TracingOTLPEndpoint))
+		}
+
+		if config.TracingOTLPInsecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+
+		exporter, err = otlptracegrpc.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter %q", config.TracingExporter)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create %s exporter: %w", config.TracingExporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+		semconv.DeploymentEnvironment(config.Environment),
+	))
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create resource: %w", err)
+	}
+
+	// the config defaults an unset ratio to 1, so 0 is only ever set on purpose and samples nothing
+	sampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.TracingSampleRatio))
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
@@ -1,34 +1,399 @@
 package util
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/rs/zerolog"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// settings of the service; each one can come from the defaults below, the config file, the environment or a flag
 type Config struct {
-	DBDriver string `mapstructure:"DB_DRIVER"`
-	DBSource string `mapstructure:"DB_SOURCE"`
+	Environment string `mapstructure:"ENVIRONMENT"`
+	LogLevel string `mapstructure:"LOG_LEVEL"`
+	TracingExporter string `mapstructure:"TRACING_EXPORTER"`
+	TracingOTLPEndpoint string `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingOTLPInsecure bool `mapstructure:"TRACING_OTLP_INSECURE"`
+	// share of traces sampled, from 0 for none to 1 for all
+	TracingSampleRatio float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	// a Postgres URL, or memory to keep the data in the process until it exits
+	DBSource string `mapstructure:"DB_SOURCE" secret:"true"`
+	// a read-only replica for account reads and history; empty sends every query to DB_SOURCE
+	DBReplicaSource string `mapstructure:"DB_REPLICA_SOURCE" secret:"true"`
+	DBMaxOpenConns int `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMinConns int `mapstructure:"DB_MIN_CONNS"`
+	DBConnMaxLifetime time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+	DBConnMaxIdleTime time.Duration `mapstructure:"DB_CONN_MAX_IDLE_TIME"`
+	DBConnectTimeout time.Duration `mapstructure:"DB_CONNECT_TIMEOUT"`
+	DBQueryTimeout time.Duration `mapstructure:"DB_QUERY_TIMEOUT"`
+	RunMigrationsOnStart bool `mapstructure:"RUN_MIGRATIONS_ON_START"`
 	ServerAddress string `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey string  `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	GRPCServerAddress string `mapstructure:"GRPC_SERVER_ADDRESS"`
+	MetricsServerAddress string `mapstructure:"METRICS_SERVER_ADDRESS"`
+	HTTPReadHeaderTimeout time.Duration `mapstructure:"HTTP_READ_HEADER_TIMEOUT"`
+	HTTPReadTimeout time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	HTTPWriteTimeout time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	HTTPIdleTimeout time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	ShutdownDrainPeriod time.Duration `mapstructure:"SHUTDOWN_DRAIN_PERIOD"`
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	TokenMaker string `mapstructure:"TOKEN_MAKER"`
+	TokenSymmetricKey string `mapstructure:"TOKEN_SYMMETRIC_KEY" secret:"true"`
 	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	PasswordHashAlgorithm string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	Argon2Memory uint32 `mapstructure:"ARGON2_MEMORY"`
+	Argon2Iterations uint32 `mapstructure:"ARGON2_ITERATIONS"`
+	Argon2Parallelism uint8 `mapstructure:"ARGON2_PARALLELISM"`
+	BcryptCost int `mapstructure:"BCRYPT_COST"`
+	PasswordMinLength int `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordRequireUpper bool `mapstructure:"PASSWORD_REQUIRE_UPPER"`
+	PasswordRequireLower bool `mapstructure:"PASSWORD_REQUIRE_LOWER"`
+	PasswordRequireDigit bool `mapstructure:"PASSWORD_REQUIRE_DIGIT"`
+	PasswordRequireSymbol bool `mapstructure:"PASSWORD_REQUIRE_SYMBOL"`
+	// a directory of SHA-1 range files of breached passwords, or a file of full hashes held in memory
+	BreachedPasswordsFile string `mapstructure:"BREACHED_PASSWORDS_FILE"`
+	StepUpTransferAmount int64 `mapstructure:"STEP_UP_TRANSFER_AMOUNT"`
+	StepUpTokenDuration time.Duration `mapstructure:"STEP_UP_TOKEN_DURATION"`
+	// seals the TOTP secrets of users in the database
+	TOTPEncryptionKey string `mapstructure:"TOTP_ENCRYPTION_KEY" secret:"true"`
+	TransferChallengeDuration time.Duration `mapstructure:"TRANSFER_CHALLENGE_DURATION"`
+	HoldDuration time.Duration `mapstructure:"HOLD_DURATION"`
+	HoldExpiryInterval time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+	InterestJobInterval time.Duration `mapstructure:"INTEREST_JOB_INTERVAL"`
+	CurrencyRefreshInterval time.Duration `mapstructure:"CURRENCY_REFRESH_INTERVAL"`
 }
 
-func LoadConfig(path string) (config Config, err error) {
-	viper.AddConfigPath(path)
-	viper.SetConfigName("app")
-	viper.SetConfigType("env")
+// token makers selectable with TOKEN_MAKER
+const (
+	TokenMakerPaseto = "paseto"
+	TokenMakerJWT = "jwt"
+)
+
+// the DB_SOURCE that runs the service on an in-memory store, for local demos
+const DBSourceMemory = "memory"
+
+// key sizes the makers of the token package accept: PASETO v2 needs exactly 32 bytes, JWT at least 32
+const (
+	pasetoKeySize = 32
+	minJWTKeySize = 32
+)
 
-	viper.AutomaticEnv()
+// the lowest layer, used when no other source sets a value
+var configDefaults = map[string]any{
+	"ENVIRONMENT": "development",
+	"LOG_LEVEL": "info",
+	"TRACING_EXPORTER": "none",
+	"TRACING_SAMPLE_RATIO": 1,
+	"DB_MAX_OPEN_CONNS": 20,
+	"DB_MIN_CONNS": 2,
+	"DB_CONN_MAX_LIFETIME": "30m",
+	"DB_CONN_MAX_IDLE_TIME": "5m",
+	"DB_CONNECT_TIMEOUT": "30s",
+	"DB_QUERY_TIMEOUT": "10s",
+	"SERVER_ADDRESS": "0.0.0.0:8080",
+	"GRPC_SERVER_ADDRESS": "0.0.0.0:9090",
+	"HTTP_READ_HEADER_TIMEOUT": "5s",
+	"HTTP_READ_TIMEOUT": "15s",
+	"HTTP_WRITE_TIMEOUT": "30s",
+	"HTTP_IDLE_TIMEOUT": "60s",
+	"SHUTDOWN_TIMEOUT": "30s",
+	"TOKEN_MAKER": TokenMakerPaseto,
+	"ACCESS_TOKEN_DURATION": "15m",
+	"REFRESH_TOKEN_DURATION": "24h",
+	"PASSWORD_HASH_ALGORITHM": "argon2id",
+	"PASSWORD_MIN_LENGTH": 8,
+	"STEP_UP_TOKEN_DURATION": "5m",
+	"TRANSFER_CHALLENGE_DURATION": "15m",
+	"HOLD_DURATION": "168h",
+}
+
+// durations where zero turns the feature off instead of being invalid
+var optionalDurations = map[string]bool{
+	"DB_QUERY_TIMEOUT": true,
+	"SHUTDOWN_DRAIN_PERIOD": true,
+	"HOLD_EXPIRY_INTERVAL": true,
+	"INTEREST_JOB_INTERVAL": true,
+	"CURRENCY_REFRESH_INTERVAL": true,
+}
 
-	err = viper.ReadInConfig()
+// where LoadConfig reads the settings from besides the defaults and the environment
+type ConfigSource struct {
+	// directory searched for app.env when File is empty
+	Path string
+	// a .env, .yaml or .toml file; CONFIG_FILE sets it too
+	File string
+	// flags registered by RegisterConfigFlags, which override every other source
+	Flags *pflag.FlagSet
+}
+
+// reads the settings from app.env in path and the environment
+func LoadConfig(path string) (Config, error) {
+	return LoadConfigFrom(ConfigSource{Path: path})
+}
+
+// reads the settings in layers: the defaults, then the config file, then the environment, then the flags that were set.
+// A setting named KEY can also be read from the file named by KEY_FILE, as Docker and Kubernetes mount secrets.
+func LoadConfigFrom(source ConfigSource) (config Config, err error) {
+	v := viper.New()
+
+	for key, value := range configDefaults {
+		v.SetDefault(key, value)
+	}
 
-	if err != nil {
+	// binding every key makes the environment visible to Unmarshal even for keys the file does not mention
+	for _, key := range configKeys() {
+		if err = v.BindEnv(key); err != nil {
+			return
+		}
+	}
+
+	file := source.File
+
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+
+	if file != "" {
+		v.SetConfigFile(file)
+		v.SetConfigType(configType(file))
+
+		if err = v.ReadInConfig(); err != nil {
+			return config, fmt.Errorf("cannot read config file %s: %w", file, err)
+		}
+	} else {
+		v.AddConfigPath(source.Path)
+		v.SetConfigName("app")
+		v.SetConfigType("env")
+
+		// the defaults and the environment are enough without app.env
+		var notFound viper.ConfigFileNotFoundError
+
+		if err = v.ReadInConfig(); err != nil && !errors.As(err, &notFound) {
+			return config, fmt.Errorf("cannot read app.env: %w", err)
+		}
+	}
+
+	if err = readSecretFiles(v); err != nil {
 		return
 	}
 
-	err = viper.Unmarshal(&config)
+	if source.Flags != nil {
+		source.Flags.Visit(func(flag *pflag.Flag) {
+			if key, ok := flagKeys[flag.Name]; ok {
+				v.Set(key, flag.Value.String())
+			}
+		})
+	}
+
+	err = v.Unmarshal(&config)
+
+	return
+}
+
+// replaces each KEY whose KEY_FILE is set with the contents of that file, without the trailing newline
+func readSecretFiles(v *viper.Viper) error {
+	for _, key := range configKeys() {
+		fileKey := key + "_FILE"
+
+		if err := v.BindEnv(fileKey); err != nil {
+			return err
+		}
+
+		path := v.GetString(fileKey)
+
+		if path == "" {
+			continue
+		}
+
+		// the environment outranks a KEY_FILE from the config file, but setting both in it is ambiguous
+		if os.Getenv(key) != "" {
+			if os.Getenv(fileKey) != "" {
+				return fmt.Errorf("both %s and %s are set", key, fileKey)
+			}
+			continue
+		}
+
+		// a relative path written in the config file is relative to that file, not to the working directory
+		if !filepath.IsAbs(path) && os.Getenv(fileKey) == "" && v.ConfigFileUsed() != "" {
+			path = filepath.Join(filepath.Dir(v.ConfigFileUsed()), path)
+		}
+
+		content, err := os.ReadFile(path)
+
+		if err != nil {
+			return fmt.Errorf("cannot read %s: %w", fileKey, err)
+		}
+
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+
+	return nil
+}
+
+func configType(file string) string {
+	switch ext := strings.TrimPrefix(filepath.Ext(file), "."); ext {
+	case "yml":
+		return "yaml"
+	case "":
+		return "env"
+	default:
+		return ext
+	}
+}
+
+// the keys of the settings, from the mapstructure tags of Config
+func configKeys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, t.Field(i).Tag.Get("mapstructure"))
+	}
+
+	return keys
+}
+
+// the config key of each flag registered by RegisterConfigFlags
+var flagKeys = map[string]string{}
+
+func flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+// adds a flag for every setting, such as --server-address for SERVER_ADDRESS
+func RegisterConfigFlags(flags *pflag.FlagSet) {
+	for _, key := range configKeys() {
+		name := flagName(key)
+		flagKeys[name] = key
+		flags.String(name, "", fmt.Sprintf("overrides %s", key))
+	}
+}
+
+// reports every invalid setting at once, so a misconfigured deploy fails with one clear message
+func (config Config) Validate() error {
+	var errs []error
+
+	required := map[string]string{
+		"DB_SOURCE": config.DBSource,
+		"SERVER_ADDRESS": config.ServerAddress,
+		"GRPC_SERVER_ADDRESS": config.GRPCServerAddress,
+	}
+
+	for _, key := range sortedKeys(required) {
+		if required[key] == "" {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+
+	// a replica of a store that lives in the process cannot exist
+	if config.DBSource == DBSourceMemory && config.DBReplicaSource != "" {
+		errs = append(errs, fmt.Errorf("DB_REPLICA_SOURCE must be empty when DB_SOURCE is %s", DBSourceMemory))
+	}
+
+	// zero open connections keeps the default size of the pool
+	if config.DBMaxOpenConns < 0 {
+		errs = append(errs, fmt.Errorf("DB_MAX_OPEN_CONNS must not be negative, got %d", config.DBMaxOpenConns))
+	}
+
+	if config.DBMinConns < 0 {
+		errs = append(errs, fmt.Errorf("DB_MIN_CONNS must not be negative, got %d", config.DBMinConns))
+	}
+
+	if config.DBMaxOpenConns > 0 && config.DBMinConns > config.DBMaxOpenConns {
+		errs = append(errs, fmt.Errorf("DB_MIN_CONNS must not exceed DB_MAX_OPEN_CONNS, got %d and %d", config.DBMinConns, config.DBMaxOpenConns))
+	}
+
+	switch config.TokenMaker {
+	case TokenMakerPaseto:
+		if len(config.TokenSymmetricKey) != pasetoKeySize {
+			errs = append(errs, fmt.Errorf("TOKEN_SYMMETRIC_KEY must be exactly %d characters for the paseto maker, got %d", pasetoKeySize, len(config.TokenSymmetricKey)))
+		}
+	case TokenMakerJWT:
+		if len(config.TokenSymmetricKey) < minJWTKeySize {
+			errs = append(errs, fmt.Errorf("TOKEN_SYMMETRIC_KEY must be at least %d characters for the jwt maker, got %d", minJWTKeySize, len(config.TokenSymmetricKey)))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("TOKEN_MAKER must be %s or %s, got %q", TokenMakerPaseto, TokenMakerJWT, config.TokenMaker))
+	}
+
+	if len(config.TOTPEncryptionKey) != EncryptionKeySize {
+		errs = append(errs, fmt.Errorf("TOTP_ENCRYPTION_KEY must be exactly %d characters, got %d", EncryptionKeySize, len(config.TOTPEncryptionKey)))
+	}
+
+	value := reflect.ValueOf(config)
+
+	for i := 0; i < value.NumField(); i++ {
+		duration, ok := value.Field(i).Interface().(time.Duration)
+
+		if !ok {
+			continue
+		}
+
+		key := value.Type().Field(i).Tag.Get("mapstructure")
+
+		if duration < 0 || (duration == 0 && !optionalDurations[key]) {
+			errs = append(errs, fmt.Errorf("%s must be greater than zero, got %s", key, duration))
+		}
+	}
+
+	if _, err := zerolog.ParseLevel(config.LogLevel); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL %q is not a log level", config.LogLevel))
+	}
+
+	if config.TracingSampleRatio < 0 || config.TracingSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1, got %g", config.TracingSampleRatio))
+	}
+
+	return errors.Join(errs...)
+}
+
+// the settings as KEY and value pairs sorted by key; with redact, secrets are masked and DB_SOURCE keeps all but its password
+func (config Config) Entries(redact bool) [][2]string {
+	value := reflect.ValueOf(config)
+	entries := make([][2]string, 0, value.NumField())
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		setting := fmt.Sprint(value.Field(i).Interface())
+
+		if redact && field.Tag.Get("secret") == "true" && setting != "" {
+			setting = redactSetting(setting)
+		}
+
+		entries = append(entries, [2]string{key, setting})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i][0] < entries[j][0] })
+
+	return entries
+}
+
+func redactSetting(setting string) string {
+	if u, err := url.Parse(setting); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+
+	return "REDACTED"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
 
-	return 
+	return keys
 }
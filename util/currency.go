@@ -1,15 +1,11 @@
 package util
 
+// currencies seeded by the migrations; which ones are enabled is kept in the currencies table
 const (
 	USD = "USD"
 	EUR = "EUR"
 	BRL = "BRL"
 )
 
-func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case USD, EUR, BRL:
-		return true
-	}
-	return false
-}
\ No newline at end of file
+// the seeded currencies, accepted until the catalogue is loaded from the database
+var DefaultCurrencies = []string{USD, EUR, BRL}
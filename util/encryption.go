@@ -0,0 +1,93 @@
+package util
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// size of the AES-256 key that seals secrets stored in the database
+const EncryptionKeySize = 32
+
+// marks values sealed by EncryptSecret and the version of their format
+const encryptedSecretPrefix = "enc:v1:"
+
+var ErrInvalidEncryptedSecret = errors.New("invalid encrypted secret")
+
+// seals the secret with AES-256-GCM under key, as enc:v1: and the base64 of the nonce and ciphertext;
+// an empty secret stays empty so "not set" needs no key to read
+func EncryptSecret(key string, secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+
+	aead, err := newSecretAEAD(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(secret), nil)
+
+	return encryptedSecretPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// opens a value sealed by EncryptSecret; an empty value stays empty
+func DecryptSecret(key string, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	if !IsEncryptedSecret(value) {
+		return "", ErrInvalidEncryptedSecret
+	}
+
+	aead, err := newSecretAEAD(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, encryptedSecretPrefix))
+
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrInvalidEncryptedSecret
+	}
+
+	secret, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+
+	if err != nil {
+		return "", ErrInvalidEncryptedSecret
+	}
+
+	return string(secret), nil
+}
+
+// reports whether the value was sealed by EncryptSecret
+func IsEncryptedSecret(value string) bool {
+	return strings.HasPrefix(value, encryptedSecretPrefix)
+}
+
+func newSecretAEAD(key string) (cipher.AEAD, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("invalid key size: must be exactly %d characters", EncryptionKeySize)
+	}
+
+	block, err := aes.NewCipher([]byte(key))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptSecret(t *testing.T) {
+	key := RandomString(EncryptionKeySize)
+	secret := RandomString(32)
+
+	encrypted, err := EncryptSecret(key, secret)
+	require.NoError(t, err)
+	require.True(t, IsEncryptedSecret(encrypted))
+	require.NotContains(t, encrypted, secret)
+
+	// a fresh nonce each time
+	again, err := EncryptSecret(key, secret)
+	require.NoError(t, err)
+	require.NotEqual(t, encrypted, again)
+
+	decrypted, err := DecryptSecret(key, encrypted)
+	require.NoError(t, err)
+	require.Equal(t, secret, decrypted)
+
+	_, err = DecryptSecret(RandomString(EncryptionKeySize), encrypted)
+	require.ErrorIs(t, err, ErrInvalidEncryptedSecret)
+
+	_, err = DecryptSecret(key, encrypted[:len(encrypted)-4])
+	require.ErrorIs(t, err, ErrInvalidEncryptedSecret)
+
+	_, err = EncryptSecret(RandomString(16), secret)
+	require.Error(t, err)
+
+	empty, err := EncryptSecret(key, "")
+	require.NoError(t, err)
+	require.Empty(t, empty)
+
+	// a plaintext value is not a secret sealed by EncryptSecret
+	_, err = DecryptSecret(key, secret)
+	require.ErrorIs(t, err, ErrInvalidEncryptedSecret)
+
+	decrypted, err = DecryptSecret(key, "")
+	require.NoError(t, err)
+	require.Empty(t, decrypted)
+}
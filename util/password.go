@@ -1,23 +1,260 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// get hashed password using bcrypt hash
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+const (
+	Argon2id = "argon2id"
+	Bcrypt   = "bcrypt"
+)
 
-	if err != nil {
-		return "", fmt.Errorf("failed to hash password: %w", err)
+var (
+	ErrMismatchedPassword   = errors.New("hashed password is not the hash of the given password")
+	ErrUnsupportedAlgorithm = errors.New("unsupported password hash algorithm")
+	ErrInvalidHash          = errors.New("invalid password hash format")
+)
+
+// hashes and verifies passwords
+type PasswordHasher interface {
+	// hashes the password using the preferred algorithm and parameters
+	HashPassword(password string) (string, error)
+
+	// checks the password against a hash produced by any supported algorithm
+	CheckPassword(password string, hashedPassword string) error
+
+	// reports whether the hash was produced with an outdated algorithm or parameters
+	NeedsRehash(hashedPassword string) bool
+}
+
+// contains the tunable parameters of argon2id
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// parameters used when none are configured
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  1,
+	Parallelism: 4,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// hashes with the configured algorithm and verifies argon2id and bcrypt hashes
+type passwordHasher struct {
+	algorithm  string
+	argon2     Argon2Params
+	bcryptCost int
+}
+
+var defaultHasher PasswordHasher = &passwordHasher{
+	algorithm:  Argon2id,
+	argon2:     DefaultArgon2Params,
+	bcryptCost: bcrypt.DefaultCost,
+}
+
+// creates a password hasher from the password settings of the config
+func NewPasswordHasher(config Config) (PasswordHasher, error) {
+	hasher := &passwordHasher{
+		algorithm:  config.PasswordHashAlgorithm,
+		argon2:     DefaultArgon2Params,
+		bcryptCost: config.BcryptCost,
+	}
+
+	if hasher.algorithm == "" {
+		hasher.algorithm = Argon2id
+	}
+
+	if hasher.algorithm != Argon2id && hasher.algorithm != Bcrypt {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, hasher.algorithm)
+	}
+
+	if config.Argon2Memory != 0 {
+		hasher.argon2.Memory = config.Argon2Memory
+	}
+
+	if config.Argon2Iterations != 0 {
+		hasher.argon2.Iterations = config.Argon2Iterations
+	}
+
+	if config.Argon2Parallelism != 0 {
+		hasher.argon2.Parallelism = config.Argon2Parallelism
+	}
+
+	if hasher.bcryptCost == 0 {
+		hasher.bcryptCost = bcrypt.DefaultCost
+	}
+
+	if hasher.bcryptCost < bcrypt.MinCost || hasher.bcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("invalid bcrypt cost %d: must be between %d and %d", hasher.bcryptCost, bcrypt.MinCost, bcrypt.MaxCost)
 	}
 
-	return string(hashedPassword), nil
+	return hasher, nil
+}
+
+// get hashed password using the default hasher
+func HashPassword(password string) (string, error) {
+	return defaultHasher.HashPassword(password)
 }
 
 // check if password provided is correct
 func CheckPassword(password string, hashedPassword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-}
\ No newline at end of file
+	return defaultHasher.CheckPassword(password, hashedPassword)
+}
+
+func (hasher *passwordHasher) HashPassword(password string) (string, error) {
+	if hasher.algorithm == Bcrypt {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), hasher.bcryptCost)
+
+		if err != nil {
+			return "", fmt.Errorf("failed to hash password: %w", err)
+		}
+
+		return string(hashedPassword), nil
+	}
+
+	salt := make([]byte, hasher.argon2.SaltLength)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	params := hasher.argon2
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return fmt.Sprintf(
+		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		Argon2id,
+		argon2.Version,
+		params.Memory,
+		params.Iterations,
+		params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (hasher *passwordHasher) CheckPassword(password string, hashedPassword string) error {
+	switch hashAlgorithm(hashedPassword) {
+	case Argon2id:
+		params, salt, key, err := decodeArgon2Hash(hashedPassword)
+
+		if err != nil {
+			return err
+		}
+
+		otherKey := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+		if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+			return ErrMismatchedPassword
+		}
+
+		return nil
+	case Bcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrMismatchedPassword
+		}
+
+		return err
+	}
+
+	return ErrUnsupportedAlgorithm
+}
+
+func (hasher *passwordHasher) NeedsRehash(hashedPassword string) bool {
+	algorithm := hashAlgorithm(hashedPassword)
+
+	if algorithm != hasher.algorithm {
+		return true
+	}
+
+	if algorithm == Bcrypt {
+		cost, err := bcrypt.Cost([]byte(hashedPassword))
+		return err != nil || cost != hasher.bcryptCost
+	}
+
+	params, _, _, err := decodeArgon2Hash(hashedPassword)
+
+	if err != nil {
+		return true
+	}
+
+	return params.Memory != hasher.argon2.Memory ||
+		params.Iterations != hasher.argon2.Iterations ||
+		params.Parallelism != hasher.argon2.Parallelism ||
+		params.KeyLength != hasher.argon2.KeyLength
+}
+
+// detects the algorithm from the format of the stored hash
+func hashAlgorithm(hashedPassword string) string {
+	switch {
+	case strings.HasPrefix(hashedPassword, "$"+Argon2id+"$"):
+		return Argon2id
+	case strings.HasPrefix(hashedPassword, "$2a$"),
+		strings.HasPrefix(hashedPassword, "$2b$"),
+		strings.HasPrefix(hashedPassword, "$2y$"):
+		return Bcrypt
+	}
+	return ""
+}
+
+// parses a hash in the $argon2id$v=19$m=...,t=...,p=...$salt$key format
+func decodeArgon2Hash(hashedPassword string) (params Argon2Params, salt []byte, key []byte, err error) {
+	fields := strings.Split(hashedPassword, "$")
+
+	if len(fields) != 6 {
+		err = ErrInvalidHash
+		return
+	}
+
+	var version int
+
+	if _, err = fmt.Sscanf(fields[2], "v=%d", &version); err != nil {
+		err = ErrInvalidHash
+		return
+	}
+
+	if version != argon2.Version {
+		err = fmt.Errorf("%w: argon2 version %d", ErrUnsupportedAlgorithm, version)
+		return
+	}
+
+	if _, err = fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		err = ErrInvalidHash
+		return
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(fields[4])
+
+	if err != nil {
+		err = ErrInvalidHash
+		return
+	}
+
+	key, err = base64.RawStdEncoding.DecodeString(fields[5])
+
+	if err != nil {
+		err = ErrInvalidHash
+		return
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return
+}
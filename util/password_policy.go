@@ -0,0 +1,246 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// most hashes a breached password list may hold, since the whole list is kept in memory
+const MaxBreachedPasswords = 1_000_000
+
+// length of the hash prefix that names a range file, as in the k-anonymity range API
+const breachedPrefixLength = 5
+
+// shortest username or email local part that a password must not contain
+const minIdentityLength = 3
+
+// rules a new password must satisfy
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	Breached      *BreachedPasswords
+}
+
+// lists every rule the password violates
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password does not meet the policy: " + strings.Join(e.Violations, "; ")
+}
+
+// creates a password policy from the config, loading the breached password list if one is configured
+func NewPasswordPolicy(config Config) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{
+		MinLength:     config.PasswordMinLength,
+		RequireUpper:  config.PasswordRequireUpper,
+		RequireLower:  config.PasswordRequireLower,
+		RequireDigit:  config.PasswordRequireDigit,
+		RequireSymbol: config.PasswordRequireSymbol,
+	}
+
+	if policy.MinLength == 0 {
+		policy.MinLength = 6
+	}
+
+	if config.BreachedPasswordsFile != "" {
+		breached, err := LoadBreachedPasswords(config.BreachedPasswordsFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		policy.Breached = breached
+	}
+
+	return policy, nil
+}
+
+// checks the password against every rule; username and email may be empty
+func (policy *PasswordPolicy) Validate(password string, username string, email string) error {
+	var violations []string
+
+	if len([]rune(password)) < policy.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters long", policy.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if policy.RequireUpper && !hasUpper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+
+	if policy.RequireLower && !hasLower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+
+	if policy.RequireDigit && !hasDigit {
+		violations = append(violations, "must contain a digit")
+	}
+
+	if policy.RequireSymbol && !hasSymbol {
+		violations = append(violations, "must contain a symbol")
+	}
+
+	lowerPassword := strings.ToLower(password)
+
+	if containsIdentity(lowerPassword, username) {
+		violations = append(violations, "must not contain the username")
+	}
+
+	if localPart, _, _ := strings.Cut(email, "@"); containsIdentity(lowerPassword, localPart) {
+		violations = append(violations, "must not contain the email address")
+	}
+
+	if policy.Breached != nil && policy.Breached.Contains(password) {
+		violations = append(violations, "must not be a password known from a data breach")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+// reports whether the lowercase password contains the username or email local part; shorter ones are
+// skipped, since a user named "a" could otherwise use no password with that letter in it
+func containsIdentity(lowerPassword string, identity string) bool {
+	return len([]rune(identity)) >= minIdentityLength && strings.Contains(lowerPassword, strings.ToLower(identity))
+}
+
+// set of SHA-1 hashes of known breached passwords, either kept sorted in memory at 20 bytes per hash
+// or read on each lookup from a directory of range files
+type BreachedPasswords struct {
+	hashes   [][sha1.Size]byte
+	rangeDir string
+}
+
+// loads a breached password list from path, in one of the formats of the offline downloads of the
+// k-anonymity range API:
+//   - a directory of range files named by the first five characters of the hash, such as 5BAA6.txt,
+//     each with one remaining hash suffix per line, optionally followed by ":count"; only the range of
+//     a password is read when it is checked, so the directory may hold the whole download
+//   - a file with one full SHA-1 hash per line, optionally followed by ":count"; it is held in memory,
+//     so it may hold at most MaxBreachedPasswords hashes (about 20 MB)
+func LoadBreachedPasswords(path string) (*BreachedPasswords, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot open breached password list: %w", err)
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot open breached password list: %w", err)
+	}
+
+	if info.IsDir() {
+		return &BreachedPasswords{rangeDir: path}, nil
+	}
+
+	return readBreachedPasswords(file, MaxBreachedPasswords)
+}
+
+// reads a breached password list of at most limit hashes
+func readBreachedPasswords(r io.Reader, limit int) (*BreachedPasswords, error) {
+	breached := &BreachedPasswords{}
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+
+		if hash == "" || strings.HasPrefix(hash, "#") {
+			continue
+		}
+
+		var sum [sha1.Size]byte
+
+		if n, err := hex.Decode(sum[:], []byte(hash)); err != nil || n != sha1.Size || len(hash) != 2*sha1.Size {
+			return nil, fmt.Errorf("invalid SHA-1 hash on line %d of breached password list", line)
+		}
+
+		if len(breached.hashes) == limit {
+			return nil, fmt.Errorf("breached password list has more than %d hashes", limit)
+		}
+
+		breached.hashes = append(breached.hashes, sum)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read breached password list: %w", err)
+	}
+
+	sort.Slice(breached.hashes, func(i, j int) bool {
+		return bytes.Compare(breached.hashes[i][:], breached.hashes[j][:]) < 0
+	})
+
+	return breached, nil
+}
+
+// reports whether the password is in the breached list; a range file that is missing or cannot be
+// read counts as empty, so a partial download only checks the ranges it has
+func (breached *BreachedPasswords) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+
+	if breached.rangeDir != "" {
+		return breached.rangeContains(strings.ToUpper(hex.EncodeToString(sum[:])))
+	}
+
+	i := sort.Search(len(breached.hashes), func(i int) bool {
+		return bytes.Compare(breached.hashes[i][:], sum[:]) >= 0
+	})
+
+	return i < len(breached.hashes) && breached.hashes[i] == sum
+}
+
+func (breached *BreachedPasswords) rangeContains(hash string) bool {
+	prefix, suffix := hash[:breachedPrefixLength], hash[breachedPrefixLength:]
+
+	file, err := os.Open(filepath.Join(breached.rangeDir, prefix+".txt"))
+
+	if err != nil {
+		return false
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		if candidate, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":"); strings.EqualFold(candidate, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,105 @@
+package util
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	policy, err := NewPasswordPolicy(Config{
+		PasswordMinLength: 8,
+		PasswordRequireUpper: true,
+		PasswordRequireLower: true,
+		PasswordRequireDigit: true,
+		PasswordRequireSymbol: true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, policy.Validate("Secret#2024", "alice", "alice@email.com"))
+
+	err = policy.Validate("short", "", "")
+	require.Error(t, err)
+
+	policyErr, ok := err.(*PasswordPolicyError)
+	require.True(t, ok)
+	require.ElementsMatch(t, []string{
+		"must be at least 8 characters long",
+		"must contain an uppercase letter",
+		"must contain a digit",
+		"must contain a symbol",
+	}, policyErr.Violations)
+
+	err = policy.Validate("Alice#2024x", "alice", "")
+	require.ErrorContains(t, err, "must not contain the username")
+
+	err = policy.Validate("Bob.Smith#1", "alice", "bob.smith@email.com")
+	require.ErrorContains(t, err, "must not contain the email address")
+
+	// a name this short would rule out every password with that letter in it
+	require.NoError(t, policy.Validate("Secret#2024", "e", "r@x.io"))
+}
+
+func TestBreachedPasswords(t *testing.T) {
+	breachedPassword := "P@ssw0rd123"
+	sum := sha1.Sum([]byte(breachedPassword))
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	content := fmt.Sprintf("# sample list\n%s:42\n%s\n", strings.ToUpper(hex.EncodeToString(sum[:])), strings.Repeat("A", 40))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	policy, err := NewPasswordPolicy(Config{BreachedPasswordsFile: path})
+	require.NoError(t, err)
+	require.NotNil(t, policy.Breached)
+
+	require.True(t, policy.Breached.Contains(breachedPassword))
+	require.False(t, policy.Breached.Contains(RandomString(12)))
+
+	err = policy.Validate(breachedPassword, "", "")
+	require.ErrorContains(t, err, "known from a data breach")
+
+	require.NoError(t, os.WriteFile(path, []byte("not-a-hash\n"), 0o600))
+	_, err = LoadBreachedPasswords(path)
+	require.ErrorContains(t, err, "line 1")
+
+	_, err = LoadBreachedPasswords(filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+
+	// the list is held in memory, so a list over the limit is refused rather than loaded
+	_, err = readBreachedPasswords(strings.NewReader(content), 1)
+	require.ErrorContains(t, err, "more than 1 hashes")
+
+	breached, err := readBreachedPasswords(strings.NewReader(content), 2)
+	require.NoError(t, err)
+	require.True(t, breached.Contains(breachedPassword))
+}
+
+func TestBreachedPasswordRanges(t *testing.T) {
+	breachedPassword := "P@ssw0rd123"
+	sum := sha1.Sum([]byte(breachedPassword))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	dir := t.TempDir()
+	content := fmt.Sprintf("%s:3\n%s:42\n", strings.Repeat("0", 35), hash[5:])
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(content), 0o600))
+
+	policy, err := NewPasswordPolicy(Config{BreachedPasswordsFile: dir})
+	require.NoError(t, err)
+
+	require.True(t, policy.Breached.Contains(breachedPassword))
+
+	require.False(t, policy.Breached.Contains(RandomString(12)))
+
+	err = policy.Validate(breachedPassword, "", "")
+	require.ErrorContains(t, err, "known from a data breach")
+
+	// ranges are read on each lookup, so the range no longer holding the suffix is seen at once
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(strings.Repeat("0", 35)+":3\n"), 0o600))
+	require.False(t, policy.Breached.Contains(breachedPassword))
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -14,16 +15,78 @@ func TestPassword(t *testing.T) {
 
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword1)
+	require.True(t, strings.HasPrefix(hashedPassword1, "$argon2id$"))
 
 	err = CheckPassword(password, hashedPassword1)
 	require.NoError(t, err)
 
 	wrongPassword := RandomString(10)
 	err = CheckPassword(wrongPassword, hashedPassword1)
-	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+	require.EqualError(t, err, ErrMismatchedPassword.Error())
 
 	hashedPassword2, err := HashPassword(password)
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword2)
 	require.NotEqual(t, hashedPassword1, hashedPassword2)
-}
\ No newline at end of file
+}
+
+func TestBcryptPassword(t *testing.T) {
+	password := RandomString(6)
+
+	hasher, err := NewPasswordHasher(Config{PasswordHashAlgorithm: Bcrypt, BcryptCost: bcrypt.MinCost})
+	require.NoError(t, err)
+
+	hashedPassword, err := hasher.HashPassword(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hashedPassword, "$2a$"))
+
+	// any hasher verifies bcrypt hashes regardless of its preferred algorithm
+	err = CheckPassword(password, hashedPassword)
+	require.NoError(t, err)
+
+	err = CheckPassword(RandomString(10), hashedPassword)
+	require.EqualError(t, err, ErrMismatchedPassword.Error())
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	password := RandomString(6)
+
+	argon2Hasher, err := NewPasswordHasher(Config{})
+	require.NoError(t, err)
+
+	bcryptHasher, err := NewPasswordHasher(Config{PasswordHashAlgorithm: Bcrypt, BcryptCost: bcrypt.MinCost})
+	require.NoError(t, err)
+
+	strongerHasher, err := NewPasswordHasher(Config{Argon2Iterations: 2})
+	require.NoError(t, err)
+
+	argon2Hash, err := argon2Hasher.HashPassword(password)
+	require.NoError(t, err)
+
+	bcryptHash, err := bcryptHasher.HashPassword(password)
+	require.NoError(t, err)
+
+	require.False(t, argon2Hasher.NeedsRehash(argon2Hash))
+	require.True(t, argon2Hasher.NeedsRehash(bcryptHash))
+	require.True(t, strongerHasher.NeedsRehash(argon2Hash))
+	require.False(t, bcryptHasher.NeedsRehash(bcryptHash))
+	require.True(t, bcryptHasher.NeedsRehash(argon2Hash))
+
+	strongerHash, err := strongerHasher.HashPassword(password)
+	require.NoError(t, err)
+	require.NoError(t, argon2Hasher.CheckPassword(password, strongerHash))
+}
+
+func TestInvalidPasswordHasher(t *testing.T) {
+	_, err := NewPasswordHasher(Config{PasswordHashAlgorithm: "md5"})
+	require.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	_, err = NewPasswordHasher(Config{PasswordHashAlgorithm: Bcrypt, BcryptCost: bcrypt.MaxCost + 1})
+	require.Error(t, err)
+
+	err = CheckPassword(RandomString(6), "plaintext")
+	require.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	err = CheckPassword(RandomString(6), "$argon2id$v=19$m=65536,t=1,p=4$bad")
+	require.ErrorIs(t, err, ErrInvalidHash)
+}
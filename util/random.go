@@ -44,13 +44,9 @@ func RandomMoney() int64 {
 
 // generates a random currency
 func RandomCurrency() string {
-	currencies := []string{
-		EUR,USD,BRL,
-	}
-
-	n := len(currencies)
+	n := len(DefaultCurrencies)
 
-	return currencies[rand.Intn(n)]
+	return DefaultCurrencies[rand.Intn(n)]
 }
 
 // generates a random email
@@ -0,0 +1,66 @@
+package util
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPReferenceValues(t *testing.T) {
+	// SHA-1 test vectors of RFC 6238, truncated to six digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	testCases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range testCases {
+		code, err := GenerateTOTPCode(secret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		require.Equal(t, expected, code)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	require.NotEmpty(t, secret)
+
+	now := time.Now()
+
+	code, err := GenerateTOTPCode(secret, now)
+	require.NoError(t, err)
+
+	counter, ok := ValidateTOTP(secret, code, now, 0)
+	require.True(t, ok)
+	require.Equal(t, now.Unix()/30, counter)
+
+	_, ok = ValidateTOTP(strings.ToLower(secret), code, now.Add(totpPeriod), 0)
+	require.True(t, ok)
+
+	_, ok = ValidateTOTP(secret, code, now.Add(3*totpPeriod), 0)
+	require.False(t, ok)
+
+	_, ok = ValidateTOTP(secret, "12345", now, 0)
+	require.False(t, ok)
+
+	_, ok = ValidateTOTP("not base32!", code, now, 0)
+	require.False(t, ok)
+
+	// a code of a step already used is a replay
+	_, ok = ValidateTOTP(secret, code, now, counter)
+	require.False(t, ok)
+
+	_, ok = ValidateTOTP(secret, code, now, counter-1)
+	require.True(t, ok)
+
+	url := TOTPURL("SimpleBank", "alice", secret)
+	require.True(t, strings.HasPrefix(url, "otpauth://totp/SimpleBank:alice?"))
+	require.Contains(t, url, "secret="+secret)
+}